@@ -0,0 +1,331 @@
+package main
+
+import (
+	"math"
+	"slices"
+)
+
+// the number of decimal places the points of the boolean operations are snapped to
+const booleanPrecision = 9
+
+// the max distance between the points considered equal in the boolean operations
+const booleanTolerance = 1e-7
+
+type booleanOp int
+
+const (
+	opUnion booleanOp = iota
+	opIntersection
+	opDifference
+)
+
+// Union returns the polygons covering both polygons
+func (p Polygon) Union(other Polygon) []Polygon {
+	return booleanOperation(p, other, opUnion)
+}
+
+// Intersection returns the polygons covered by both polygons
+func (p Polygon) Intersection(other Polygon) []Polygon {
+	return booleanOperation(p, other, opIntersection)
+}
+
+// Difference returns the polygons covered by the polygon but not by the other
+func (p Polygon) Difference(other Polygon) []Polygon {
+	return booleanOperation(p, other, opDifference)
+}
+
+// Xor returns the polygons covered by exactly one of the polygons
+func (p Polygon) Xor(other Polygon) []Polygon {
+	return append(p.Difference(other), other.Difference(p)...)
+}
+
+// UnionAll returns the union of all polygons
+func UnionAll(polys []Polygon) []Polygon {
+	var result []Polygon
+	for _, poly := range polys {
+		// the polygon absorbs the result polygons it overlaps
+		for merged := true; merged; {
+			merged = false
+			for j := 0; j < len(result); j++ {
+				if !boundsTouch(poly, result[j], booleanTolerance) {
+					continue
+				}
+				union := poly.Union(result[j])
+				if len(union) != 1 {
+					continue
+				}
+				poly = union[0]
+				result = slices.Delete(result, j, j+1)
+				merged = true
+				break
+			}
+		}
+		result = append(result, poly)
+	}
+	return result
+}
+
+// edge is a directed edge with the material on the right side
+type edge struct {
+	Line
+	// true if the edge has been added to a ring
+	used bool
+}
+
+// booleanOperation computes the result by selecting the split edges of both polygons
+// depending on whether they lie inside, outside or on the boundary of the other polygon
+// and linking the selected edges into rings.
+func booleanOperation(a, b Polygon, op booleanOp) []Polygon {
+	edgesA := materialEdges(a)
+	edgesB := materialEdges(b)
+
+	splitA := splitEdges(edgesA, edgesB)
+	splitB := splitEdges(edgesB, edgesA)
+
+	var selected []edge
+	for _, e := range splitA {
+		switch classifyEdge(e, b, edgesB) {
+		case edgeOutside:
+			if op == opUnion || op == opDifference {
+				selected = append(selected, edge{Line: e})
+			}
+		case edgeInside:
+			if op == opIntersection {
+				selected = append(selected, edge{Line: e})
+			}
+		case edgeSameDirection:
+			if op == opUnion || op == opIntersection {
+				selected = append(selected, edge{Line: e})
+			}
+		case edgeOppositeDirection:
+			if op == opDifference {
+				selected = append(selected, edge{Line: e})
+			}
+		}
+	}
+	for _, e := range splitB {
+		switch classifyEdge(e, a, edgesA) {
+		case edgeOutside:
+			if op == opUnion {
+				selected = append(selected, edge{Line: e})
+			}
+		case edgeInside:
+			if op == opIntersection {
+				selected = append(selected, edge{Line: e})
+			}
+			if op == opDifference {
+				selected = append(selected, edge{Line: Line{Start: e.End, End: e.Start}})
+			}
+		}
+	}
+
+	return buildPolygons(linkEdges(selected))
+}
+
+// materialEdges returns the edges of the polygon directed so that the material is on the right:
+// the clockwise outer ring and the counter-clockwise inner rings
+func materialEdges(p Polygon) []Line {
+	var edges []Line
+	for i, ring := range p.rings() {
+		clockwise := ring.Area() > 0
+		reverse := clockwise == (i > 0)
+		for j := 0; j < len(ring)-1; j++ {
+			e := Line{Start: snap(ring[j]), End: snap(ring[j+1])}
+			if e.Start == e.End {
+				continue
+			}
+			if reverse {
+				e.Start, e.End = e.End, e.Start
+			}
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// splitEdges splits the edges at the points where they meet the other edges
+func splitEdges(edges, others []Line) []Line {
+	var split []Line
+	for _, e := range edges {
+		params := []float64{0, 1}
+		for _, o := range others {
+			params = append(params, intersectionParams(e, o)...)
+		}
+		slices.Sort(params)
+
+		start := e.Start
+		for _, t := range params[1:] {
+			end := snap(interpolate(e.Start, e.End, t))
+			if t == 1 {
+				end = e.End
+			}
+			if end.Distance(start) > booleanTolerance {
+				split = append(split, Line{Start: start, End: end})
+				start = end
+			}
+		}
+	}
+	return split
+}
+
+// intersectionParams returns the positions along the edge e (0 is the start, 1 is the end)
+// where the edge o touches it
+func intersectionParams(e, o Line) []float64 {
+	d := NewPoint(e.End.X-e.Start.X, e.End.Y-e.Start.Y)
+	f := NewPoint(o.End.X-o.Start.X, o.End.Y-o.Start.Y)
+	length := math.Hypot(d.X, d.Y)
+	denom := cross(d, f)
+
+	if math.Abs(denom) < booleanTolerance*length*math.Hypot(f.X, f.Y) {
+		// the edges are parallel, the end points of o lying on e split it
+		var params []float64
+		for _, pt := range []Point{o.Start, o.End} {
+			if distanceToLine(pt, e.Start, direction(e.Start, e.End)) > booleanTolerance {
+				continue
+			}
+			t := dot(d, NewPoint(pt.X-e.Start.X, pt.Y-e.Start.Y)) / (length * length)
+			if t > 0 && t < 1 {
+				params = append(params, t)
+			}
+		}
+		return params
+	}
+
+	w := NewPoint(o.Start.X-e.Start.X, o.Start.Y-e.Start.Y)
+	t := cross(w, f) / denom
+	u := cross(w, d) / denom
+	if t > 0 && t < 1 && u >= -booleanTolerance && u <= 1+booleanTolerance {
+		return []float64{t}
+	}
+	return nil
+}
+
+type edgeClass int
+
+const (
+	edgeOutside edgeClass = iota
+	edgeInside
+	edgeSameDirection
+	edgeOppositeDirection
+)
+
+// classifyEdge returns the position of the edge relative to the polygon
+func classifyEdge(e Line, poly Polygon, edges []Line) edgeClass {
+	middle := interpolate(e.Start, e.End, 0.5)
+	dir := direction(e.Start, e.End)
+	for _, o := range edges {
+		if distanceToSegment(middle, o.Start, o.End) > booleanTolerance {
+			continue
+		}
+		if dot(dir, direction(o.Start, o.End)) > 0 {
+			return edgeSameDirection
+		}
+		return edgeOppositeDirection
+	}
+
+	if poly.Contains(middle) {
+		return edgeInside
+	}
+	return edgeOutside
+}
+
+// linkEdges joins the edges into closed rings. At a point with several outgoing edges
+// the rightmost turn is taken, so the rings do not cross each other.
+func linkEdges(edges []edge) []Ring {
+	outgoing := make(map[Point][]int)
+	for i, e := range edges {
+		outgoing[e.Start] = append(outgoing[e.Start], i)
+	}
+
+	var rings []Ring
+	for i := range edges {
+		if edges[i].used {
+			continue
+		}
+
+		ring := Ring{edges[i].Start}
+		current := i
+		for {
+			edges[current].used = true
+			ring = append(ring, edges[current].End)
+			if edges[current].End == ring[0] {
+				break
+			}
+
+			next := nextEdge(edges, outgoing[edges[current].End], edges[current].Line)
+			if next == -1 {
+				// the edges do not form a closed ring
+				ring = nil
+				break
+			}
+			current = next
+		}
+
+		if len(ring) > 3 {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+func nextEdge(edges []edge, candidates []int, incoming Line) int {
+	in := direction(incoming.Start, incoming.End)
+	best, bestTurn := -1, math.Inf(1)
+	for _, c := range candidates {
+		if edges[c].used {
+			continue
+		}
+		out := direction(edges[c].Start, edges[c].End)
+		// the positive turn is to the left
+		turn := math.Atan2(cross(in, out), dot(in, out))
+		if turn < bestTurn {
+			best, bestTurn = c, turn
+		}
+	}
+	return best
+}
+
+// buildPolygons assigns the holes to the outer rings containing them.
+// The clockwise rings are outer rings, the counter-clockwise rings are holes.
+func buildPolygons(rings []Ring) []Polygon {
+	var outers, holes []Ring
+	for _, ring := range rings {
+		if ring.Area() > 0 {
+			outers = append(outers, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	inners := make([][]Ring, len(outers))
+	for _, hole := range holes {
+		// the point next to the hole in the material
+		probe := add(interpolate(hole[0], hole[1], 0.5),
+			scale(normal(direction(hole[0], hole[1]), false), probeDistance))
+
+		owner := -1
+		for i, outer := range outers {
+			if outer.Contains(probe) && (owner == -1 || outer.Area() < outers[owner].Area()) {
+				owner = i
+			}
+		}
+		if owner == -1 {
+			continue
+		}
+
+		// the holes of the polygon are clockwise
+		reversed := slices.Clone(hole)
+		slices.Reverse(reversed)
+		inners[owner] = append(inners[owner], reversed)
+	}
+
+	polys := make([]Polygon, len(outers))
+	for i, outer := range outers {
+		polys[i] = NewPolygon(outer, inners[i]...)
+	}
+	return polys
+}
+
+func snap(pt Point) Point {
+	return NewPoint(toFixed(pt.X, booleanPrecision), toFixed(pt.Y, booleanPrecision))
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func totalArea(polys []Polygon) float64 {
+	var area float64
+	for _, p := range polys {
+		area += p.Area()
+	}
+	return area
+}
+
+func TestPolygon_Boolean(t *testing.T) {
+	square := NewPolygon(NewRectangle(0, 0, 4, 4))
+
+	tests := []struct {
+		name         string
+		a, b         Polygon
+		union        float64
+		intersection float64
+		difference   float64
+		numUnion     int
+	}{
+		{
+			name:         "overlapping squares",
+			a:            square,
+			b:            NewPolygon(NewRectangle(2, 2, 4, 4)),
+			union:        28,
+			intersection: 4,
+			difference:   12,
+			numUnion:     1,
+		},
+		{
+			name:         "adjacent squares",
+			a:            square,
+			b:            NewPolygon(NewRectangle(4, 0, 4, 4)),
+			union:        32,
+			intersection: 0,
+			difference:   16,
+			numUnion:     1,
+		},
+		{
+			name:         "disjoint squares",
+			a:            square,
+			b:            NewPolygon(NewRectangle(10, 10, 4, 4)),
+			union:        32,
+			intersection: 0,
+			difference:   16,
+			numUnion:     2,
+		},
+		{
+			name:         "coincident squares",
+			a:            square,
+			b:            square,
+			union:        16,
+			intersection: 16,
+			difference:   0,
+			numUnion:     1,
+		},
+		{
+			name:         "square inside square",
+			a:            square,
+			b:            NewPolygon(NewRectangle(1, 1, 2, 2)),
+			union:        16,
+			intersection: 4,
+			difference:   12,
+			numUnion:     1,
+		},
+		{
+			name:         "square inside hole",
+			a:            NewPolygon(NewRectangle(0, 0, 10, 10), NewRectangle(2, 2, 6, 6)),
+			b:            NewPolygon(NewRectangle(4, 4, 2, 2)),
+			union:        68,
+			intersection: 0,
+			difference:   64,
+			numUnion:     2,
+		},
+		{
+			name:         "square across hole",
+			a:            NewPolygon(NewRectangle(0, 0, 10, 10), NewRectangle(2, 2, 6, 6)),
+			b:            NewPolygon(NewRectangle(1, 4, 2, 8)),
+			union:        64 + 12,
+			intersection: 4,
+			difference:   60,
+			numUnion:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			union := tt.a.Union(tt.b)
+			assert.Len(t, union, tt.numUnion)
+			assert.InDelta(t, tt.union, totalArea(union), epsilon)
+			assert.InDelta(t, tt.intersection, totalArea(tt.a.Intersection(tt.b)), epsilon)
+			assert.InDelta(t, tt.difference, totalArea(tt.a.Difference(tt.b)), epsilon)
+		})
+	}
+}
+
+func TestPolygon_Difference_Hole(t *testing.T) {
+	got := NewPolygon(NewRectangle(0, 0, 4, 4)).Difference(NewPolygon(NewRectangle(1, 1, 2, 2)))
+
+	assert.Len(t, got, 1)
+	assert.Len(t, got[0].innerRings, 1)
+	// the holes are clockwise as the other rings of the polygon
+	assert.InDelta(t, 4, got[0].innerRings[0].Area(), epsilon)
+}
+
+// randomPolygon returns a star-shaped polygon around the center
+func randomPolygon(rnd *rand.Rand, center Point) Polygon {
+	n := 3 + rnd.Intn(8)
+	ring := make(Ring, 0, n+1)
+	for i := 0; i < n; i++ {
+		angle := -2 * math.Pi * (float64(i) + rnd.Float64()*0.8) / float64(n)
+		radius := 1 + rnd.Float64()*4
+		ring = append(ring, NewPoint(
+			toFixed(center.X+radius*math.Cos(angle), 4),
+			toFixed(center.Y+radius*math.Sin(angle), 4),
+		))
+	}
+	return NewPolygon(append(ring, ring[0]))
+}
+
+func TestPolygon_BooleanAreaIdentities(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		a := randomPolygon(rnd, NewPoint(0, 0))
+		b := randomPolygon(rnd, NewPoint(rnd.Float64()*6-3, rnd.Float64()*6-3))
+
+		union := totalArea(a.Union(b))
+		intersection := totalArea(a.Intersection(b))
+		difference := totalArea(a.Difference(b))
+		xor := totalArea(a.Xor(b))
+
+		const delta = 1e-6
+		assert.InDelta(t, a.Area()+b.Area(), union+intersection, delta, "case %d", i)
+		assert.InDelta(t, a.Area()-intersection, difference, delta, "case %d", i)
+		assert.InDelta(t, union-intersection, xor, delta, "case %d", i)
+		assert.GreaterOrEqual(t, intersection, -delta)
+		assert.LessOrEqual(t, intersection, min(a.Area(), b.Area())+delta)
+	}
+}
+
+func TestUnionAll(t *testing.T) {
+	got := UnionAll([]Polygon{
+		NewPolygon(NewRectangle(0, 0, 2, 2)),
+		NewPolygon(NewRectangle(10, 0, 2, 2)),
+		NewPolygon(NewRectangle(1, 0, 10, 2)),
+	})
+
+	assert.Len(t, got, 2)
+	assert.InDelta(t, 4+20-2+4, totalArea(got), epsilon)
+}
+
+func TestPolygon_BooleanAreaIdentitiesWithHoles(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+
+	// the integer coordinates produce many coincident edges and vertices
+	randomRectangle := func(size int) Ring {
+		return NewRectangle(float64(rnd.Intn(8)), float64(rnd.Intn(8)), float64(1+rnd.Intn(size)), float64(1+rnd.Intn(size)))
+	}
+
+	for i := 0; i < 200; i++ {
+		a := NewPolygon(NewRectangle(0, 0, 10, 10), NewRectangle(float64(1+rnd.Intn(4)), float64(1+rnd.Intn(4)), 4, 4))
+		b := NewPolygon(randomRectangle(6))
+
+		union := totalArea(a.Union(b))
+		intersection := totalArea(a.Intersection(b))
+		difference := totalArea(a.Difference(b))
+		xor := totalArea(a.Xor(b))
+
+		const delta = 1e-6
+		assert.InDelta(t, a.Area()+b.Area(), union+intersection, delta, "case %d", i)
+		assert.InDelta(t, a.Area()-intersection, difference, delta, "case %d", i)
+		assert.InDelta(t, union-intersection, xor, delta, "case %d", i)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import "math"
+
+// the max distance between the edges which are considered coincident
+const commonLineTolerance = 0.001
+
+// CommonLine represents a straight section shared by the contours of two placed parts.
+// The section is cut once by the contour of the first part.
+type CommonLine struct {
+	Line  Line
+	Parts [2]int
+}
+
+// Length returns the length of the line
+func (l Line) Length() float64 {
+	return l.Start.Distance(l.End)
+}
+
+// FindCommonLines returns the collinear coincident edges of the placed parts.
+// The coincident sections of the adjacent edges are merged into a single line.
+func FindCommonLines(parts []Polygon, tolerance float64) []CommonLine {
+	var common []CommonLine
+	for i := 0; i < len(parts); i++ {
+		for j := i + 1; j < len(parts); j++ {
+			if !boundsTouch(parts[i], parts[j], tolerance) {
+				continue
+			}
+
+			var lines []Line
+			for _, a := range parts[i].rings() {
+				for _, b := range parts[j].rings() {
+					lines = append(lines, coincidentSections(a, b, tolerance)...)
+				}
+			}
+
+			for _, line := range mergeCollinear(lines, tolerance) {
+				common = append(common, CommonLine{Line: line, Parts: [2]int{i, j}})
+			}
+		}
+	}
+	return common
+}
+
+// CommonLinesLength returns the length of the cut saved by cutting the common lines once
+func CommonLinesLength(lines []CommonLine) float64 {
+	var length float64
+	for _, l := range lines {
+		length += l.Line.Length()
+	}
+	return length
+}
+
+// ShareCommonLines marks the common lines on the contours of the second part,
+// so they are not cut twice
+func (s *CutSequence) ShareCommonLines(lines []CommonLine) {
+	for i, contour := range s.Contours {
+		for _, l := range lines {
+			if l.Parts[1] == contour.Part {
+				s.Contours[i].Shared = append(s.Contours[i].Shared, l.Line)
+			}
+		}
+	}
+}
+
+// rings returns the outer ring and the inner rings of the polygon
+func (p Polygon) rings() []Ring {
+	return append([]Ring{p.outerRing}, p.innerRings...)
+}
+
+func boundsTouch(a, b Polygon, tolerance float64) bool {
+	aminx, aminy, amaxx, amaxy := a.Bounds()
+	bminx, bminy, bmaxx, bmaxy := b.Bounds()
+	return aminx <= bmaxx+tolerance && bminx <= amaxx+tolerance &&
+		aminy <= bmaxy+tolerance && bminy <= amaxy+tolerance
+}
+
+// coincidentSections returns the sections shared by the edges of the rings
+func coincidentSections(a, b Ring, tolerance float64) []Line {
+	var sections []Line
+	for k := 0; k < len(a)-1; k++ {
+		edge := Line{Start: a[k], End: a[k+1]}
+		for l := 0; l < len(b)-1; l++ {
+			from, to, ok := collinearOverlap(edge, Line{Start: b[l], End: b[l+1]}, tolerance)
+			if !ok {
+				continue
+			}
+			dir := direction(edge.Start, edge.End)
+			sections = append(sections, Line{
+				Start: add(edge.Start, scale(dir, from)),
+				End:   add(edge.Start, scale(dir, to)),
+			})
+		}
+	}
+	return sections
+}
+
+// collinearOverlap returns the overlap of the collinear lines as distances along the line a
+func collinearOverlap(a, b Line, tolerance float64) (float64, float64, bool) {
+	length := a.Length()
+	if length == 0 || b.Length() == 0 {
+		return 0, 0, false
+	}
+
+	dir := direction(a.Start, a.End)
+	if distanceToLine(b.Start, a.Start, dir) > tolerance || distanceToLine(b.End, a.Start, dir) > tolerance {
+		return 0, 0, false
+	}
+
+	t1 := dot(dir, NewPoint(b.Start.X-a.Start.X, b.Start.Y-a.Start.Y))
+	t2 := dot(dir, NewPoint(b.End.X-a.Start.X, b.End.Y-a.Start.Y))
+	from := max(0, min(t1, t2))
+	to := min(length, max(t1, t2))
+	if to-from <= tolerance {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// mergeCollinear joins the collinear lines that touch or overlap each other
+func mergeCollinear(lines []Line, tolerance float64) []Line {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(lines) && !merged; i++ {
+			for j := i + 1; j < len(lines); j++ {
+				line, ok := joinCollinear(lines[i], lines[j], tolerance)
+				if !ok {
+					continue
+				}
+				lines[i] = line
+				lines = append(lines[:j], lines[j+1:]...)
+				merged = true
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// joinCollinear returns the line covering both lines if they are collinear and touch
+func joinCollinear(a, b Line, tolerance float64) (Line, bool) {
+	dir := direction(a.Start, a.End)
+	if distanceToLine(b.Start, a.Start, dir) > tolerance || distanceToLine(b.End, a.Start, dir) > tolerance {
+		return Line{}, false
+	}
+
+	t1 := dot(dir, NewPoint(b.Start.X-a.Start.X, b.Start.Y-a.Start.Y))
+	t2 := dot(dir, NewPoint(b.End.X-a.Start.X, b.End.Y-a.Start.Y))
+	if max(t1, t2) < -tolerance || min(t1, t2) > a.Length()+tolerance {
+		return Line{}, false
+	}
+
+	from := min(0, t1, t2)
+	to := max(a.Length(), t1, t2)
+	return Line{
+		Start: add(a.Start, scale(dir, from)),
+		End:   add(a.Start, scale(dir, to)),
+	}, true
+}
+
+// distanceToLine returns the distance from the point to the line through the origin with the given direction
+func distanceToLine(pt, origin, dir Point) float64 {
+	return math.Abs(cross(dir, NewPoint(pt.X-origin.X, pt.Y-origin.Y)))
+}
+
+func dot(a, b Point) float64 {
+	return a.X*b.X + a.Y*b.Y
+}
+
+func cross(a, b Point) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
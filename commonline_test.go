@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCommonLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []Polygon
+		expected []CommonLine
+	}{
+		{
+			name: "adjacent squares",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(2, 0, 2, 2)),
+			},
+			expected: []CommonLine{
+				{Line: Line{Start: NewPoint(2, 2), End: NewPoint(2, 0)}, Parts: [2]int{0, 1}},
+			},
+		},
+		{
+			name: "partial overlap",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(1, 2, 1, 4)),
+			},
+			expected: []CommonLine{
+				{Line: Line{Start: NewPoint(1, 2), End: NewPoint(2, 2)}, Parts: [2]int{0, 1}},
+			},
+		},
+		{
+			name: "split edge is merged",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(Ring{{2, 0}, {2, 1}, {2, 2}, {4, 2}, {4, 0}, {2, 0}}),
+			},
+			expected: []CommonLine{
+				{Line: Line{Start: NewPoint(2, 2), End: NewPoint(2, 0)}, Parts: [2]int{0, 1}},
+			},
+		},
+		{
+			name: "corner contact",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(2, 2, 2, 2)),
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindCommonLines(tt.parts, commonLineTolerance)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestCutPath_SharedLine(t *testing.T) {
+	contour := CutContour{
+		Ring:   NewRectangle(2, 0, 2, 2),
+		Shared: []Line{{Start: NewPoint(2, 2), End: NewPoint(2, 0)}},
+	}
+
+	got := NewCutPath(contour, LeadOptions{})
+	assert.Equal(t, [][]Point{{{2, 2}, {4, 2}, {4, 0}, {2, 0}}}, got.Cuts)
+}
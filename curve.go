@@ -0,0 +1,301 @@
+package main
+
+import (
+	"math"
+	"slices"
+)
+
+// the default max distance between a curve and its flattened approximation
+const defaultFlattenTolerance = 0.01
+
+// Curve represents a segment of a contour. The segment starts at the end of the previous one.
+type Curve interface {
+	// End returns the end point of the curve
+	End() Point
+	// Flatten returns the points approximating the curve after the start point
+	// so that the distance between the curve and the chords is within the tolerance
+	Flatten(start Point, tolerance float64) []Point
+	// Transform returns the curve mapped by the similarity transformation
+	Transform(fn func(Point) Point) Curve
+}
+
+// LineSegment is a straight segment
+type LineSegment struct {
+	To Point
+}
+
+func (l LineSegment) End() Point {
+	return l.To
+}
+
+func (l LineSegment) Flatten(Point, float64) []Point {
+	return []Point{l.To}
+}
+
+func (l LineSegment) Transform(fn func(Point) Point) Curve {
+	return LineSegment{To: fn(l.To)}
+}
+
+// ArcSegment is a circular arc around the center.
+// The arc ending at its start point is a full circle.
+type ArcSegment struct {
+	Center    Point
+	To        Point
+	Clockwise bool
+}
+
+func (a ArcSegment) End() Point {
+	return a.To
+}
+
+// Sweep returns the signed angle of the arc in radians, positive if counter-clockwise
+func (a ArcSegment) Sweep(start Point) float64 {
+	from := math.Atan2(start.Y-a.Center.Y, start.X-a.Center.X)
+	to := math.Atan2(a.To.Y-a.Center.Y, a.To.X-a.Center.X)
+
+	sweep := math.Mod(to-from+4*math.Pi, 2*math.Pi)
+	if a.Clockwise {
+		sweep -= 2 * math.Pi
+		if sweep <= -2*math.Pi+epsilon {
+			sweep = -2 * math.Pi
+		}
+	} else if sweep < epsilon {
+		sweep = 2 * math.Pi
+	}
+	return sweep
+}
+
+func (a ArcSegment) Flatten(start Point, tolerance float64) []Point {
+	radius := start.Distance(a.Center)
+	sweep := a.Sweep(start)
+
+	// the max angle of a chord which deviates from the arc by the tolerance
+	step := math.Pi / 2
+	if tolerance < radius {
+		step = 2 * math.Acos(1-tolerance/radius)
+	}
+	n := int(math.Ceil(math.Abs(sweep) / step))
+
+	from := math.Atan2(start.Y-a.Center.Y, start.X-a.Center.X)
+	points := make([]Point, 0, n)
+	for i := 1; i < n; i++ {
+		angle := from + sweep*float64(i)/float64(n)
+		points = append(points, NewPoint(
+			a.Center.X+radius*math.Cos(angle),
+			a.Center.Y+radius*math.Sin(angle),
+		))
+	}
+	return append(points, a.To)
+}
+
+func (a ArcSegment) Transform(fn func(Point) Point) Curve {
+	return ArcSegment{
+		Center: fn(a.Center),
+		To:     fn(a.To),
+		// the reflection changes the direction of the arc
+		Clockwise: a.Clockwise != reflects(fn),
+	}
+}
+
+// QuadBezier is a quadratic Bezier curve
+type QuadBezier struct {
+	Control Point
+	To      Point
+}
+
+func (q QuadBezier) End() Point {
+	return q.To
+}
+
+func (q QuadBezier) Flatten(start Point, tolerance float64) []Point {
+	return q.Cubic(start).Flatten(start, tolerance)
+}
+
+func (q QuadBezier) Transform(fn func(Point) Point) Curve {
+	return QuadBezier{Control: fn(q.Control), To: fn(q.To)}
+}
+
+// Cubic returns the same curve as a cubic Bezier curve
+func (q QuadBezier) Cubic(start Point) CubicBezier {
+	return CubicBezier{
+		Control1: interpolate(start, q.Control, 2.0/3),
+		Control2: interpolate(q.To, q.Control, 2.0/3),
+		To:       q.To,
+	}
+}
+
+// CubicBezier is a cubic Bezier curve
+type CubicBezier struct {
+	Control1, Control2 Point
+	To                 Point
+}
+
+func (c CubicBezier) End() Point {
+	return c.To
+}
+
+// max depth of the subdivision of the Bezier curve
+const bezierMaxDepth = 16
+
+func (c CubicBezier) Flatten(start Point, tolerance float64) []Point {
+	return flattenCubic(start, c.Control1, c.Control2, c.To, tolerance, 0)
+}
+
+func (c CubicBezier) Transform(fn func(Point) Point) Curve {
+	return CubicBezier{Control1: fn(c.Control1), Control2: fn(c.Control2), To: fn(c.To)}
+}
+
+// flattenCubic subdivides the curve in halves until its control points lie
+// within the tolerance from the chord. The curve lies inside the hull of its control points.
+// https://en.wikipedia.org/wiki/De_Casteljau%27s_algorithm
+func flattenCubic(p0, p1, p2, p3 Point, tolerance float64, depth int) []Point {
+	if depth >= bezierMaxDepth ||
+		distanceToSegment(p1, p0, p3) <= tolerance && distanceToSegment(p2, p0, p3) <= tolerance {
+		return []Point{p3}
+	}
+
+	p01, p12, p23 := interpolate(p0, p1, 0.5), interpolate(p1, p2, 0.5), interpolate(p2, p3, 0.5)
+	p012, p123 := interpolate(p01, p12, 0.5), interpolate(p12, p23, 0.5)
+	middle := interpolate(p012, p123, 0.5)
+
+	return append(
+		flattenCubic(p0, p01, p012, middle, tolerance, depth+1),
+		flattenCubic(middle, p123, p23, p3, tolerance, depth+1)...,
+	)
+}
+
+// Contour represents a closed boundary built from the curves
+type Contour struct {
+	Start  Point
+	Curves []Curve
+}
+
+// NewCircleContour returns a clockwise circle
+func NewCircleContour(x, y, radius float64) Contour {
+	start := NewPoint(x-radius, y)
+	return Contour{
+		Start:  start,
+		Curves: []Curve{ArcSegment{Center: NewPoint(x, y), To: start, Clockwise: true}},
+	}
+}
+
+// Flatten returns the closed ring approximating the contour within the tolerance
+func (c Contour) Flatten(tolerance float64) Ring {
+	ring, _ := c.flatten(tolerance)
+	return ring
+}
+
+// flatten returns the closed ring approximating the contour and
+// whether each edge of the ring is a chord of a curve
+func (c Contour) flatten(tolerance float64) (Ring, []bool) {
+	ring := Ring{c.Start}
+	var chords []bool
+	current := c.Start
+	for _, curve := range c.Curves {
+		_, line := curve.(LineSegment)
+		for _, pt := range curve.Flatten(current, tolerance) {
+			if pt != ring[len(ring)-1] {
+				ring = append(ring, pt)
+				chords = append(chords, !line)
+			}
+		}
+		current = curve.End()
+	}
+	if !ring.IsClosed() {
+		ring = append(ring, c.Start)
+		chords = append(chords, false)
+	}
+	return ring, chords
+}
+
+// cover returns the flattened contour with the chords of the curves moved
+// to the scrap by the tolerance, so the ring contains the material bounded by the curves
+func (c Contour) cover(tolerance float64, hole bool) Ring {
+	ring, chords := c.flatten(tolerance)
+	shifts := make([]float64, len(chords))
+	for i, chord := range chords {
+		if chord {
+			shifts[i] = tolerance
+		}
+	}
+	return ring.offsetEdges(shifts, (ring.Area() > 0) != hole)
+}
+
+// StartNear returns the same contour starting at the end of its curve nearest to the point
+func (c Contour) StartNear(pt Point) Contour {
+	curves := c.Curves
+	if len(curves) > 0 && curves[len(curves)-1].End() != c.Start {
+		// the contour is closed by the line back to the start
+		curves = append(curves[:len(curves):len(curves)], LineSegment{To: c.Start})
+	}
+
+	if len(curves) < 2 {
+		return c
+	}
+
+	nearest := 0
+	for i, curve := range curves[:len(curves)-1] {
+		if curve.End().Distance(pt) < curves[nearest].End().Distance(pt) {
+			nearest = i
+		}
+	}
+	if c.Start.Distance(pt) <= curves[nearest].End().Distance(pt) {
+		return c
+	}
+
+	rotated := append(slices.Clone(curves[nearest+1:]), curves[:nearest+1]...)
+	return Contour{Start: curves[nearest].End(), Curves: rotated}
+}
+
+func (c Contour) Transform(fn func(Point) Point) Contour {
+	curves := make([]Curve, len(c.Curves))
+	for i, curve := range c.Curves {
+		curves[i] = curve.Transform(fn)
+	}
+	return Contour{Start: fn(c.Start), Curves: curves}
+}
+
+// Shape represents a part bounded by the curves, the counterpart of Polygon
+type Shape struct {
+	Outer Contour
+	Inner []Contour
+}
+
+// Flatten returns the polygon approximating the shape. The chords lie inside the convex curves,
+// so they are moved away from the material by the tolerance and the polygon covers the shape.
+func (s Shape) Flatten(tolerance float64) Polygon {
+	var inners []Ring
+	for _, inner := range s.Inner {
+		inners = append(inners, inner.cover(tolerance, true))
+	}
+	return NewPolygon(s.Outer.cover(tolerance, false), inners...)
+}
+
+func (s Shape) Transform(fn func(Point) Point) Shape {
+	var inners []Contour
+	for _, inner := range s.Inner {
+		inners = append(inners, inner.Transform(fn))
+	}
+	return Shape{Outer: s.Outer.Transform(fn), Inner: inners}
+}
+
+// Contours returns the outer contour and the inner contours of the shape
+func (s Shape) Contours() []Contour {
+	return append([]Contour{s.Outer}, s.Inner...)
+}
+
+// rotation returns the transformation applied to the points by Polygon.Rotate
+func rotation(poly Polygon, angle float64) func(Point) Point {
+	center := poly.outerRing.Centroid()
+	minx, miny, _, _ := NewPolygon(poly.outerRing.Rotate(angle, center)).Bounds()
+	return func(pt Point) Point {
+		rotated := Ring{pt}.Rotate(angle, center)[0]
+		return NewPoint(rotated.X-minx, rotated.Y-miny)
+	}
+}
+
+// reflects returns true if the transformation changes the orientation of the plane
+func reflects(fn func(Point) Point) bool {
+	o, x, y := fn(NewPoint(0, 0)), fn(NewPoint(1, 0)), fn(NewPoint(0, 1))
+	return cross(NewPoint(x.X-o.X, x.Y-o.Y), NewPoint(y.X-o.X, y.Y-o.Y)) < 0
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContour_Flatten(t *testing.T) {
+	const tolerance = 0.01
+
+	tests := []struct {
+		name    string
+		contour Contour
+		area    float64
+	}{
+		{
+			name:    "circle",
+			contour: NewCircleContour(5, 5, 2),
+			area:    math.Pi * 4,
+		},
+		{
+			name: "half circle",
+			contour: Contour{
+				Start: NewPoint(0, 0),
+				Curves: []Curve{
+					ArcSegment{Center: NewPoint(2, 0), To: NewPoint(4, 0), Clockwise: true},
+					LineSegment{To: NewPoint(0, 0)},
+				},
+			},
+			area: math.Pi * 2,
+		},
+		{
+			name: "quadratic bezier",
+			contour: Contour{
+				Start: NewPoint(0, 0),
+				Curves: []Curve{
+					QuadBezier{Control: NewPoint(1, 2), To: NewPoint(2, 0)},
+				},
+			},
+			// the area under the parabola y = 2x - x^2
+			area: 4.0 / 3,
+		},
+		{
+			name: "cubic bezier",
+			contour: Contour{
+				Start: NewPoint(0, 0),
+				Curves: []Curve{
+					CubicBezier{Control1: NewPoint(0, 3), Control2: NewPoint(3, 3), To: NewPoint(3, 0)},
+				},
+			},
+			// the area under the curve x = 9t^2 - 6t^3, y = 9t(1-t)
+			area: 5.4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := tt.contour.Flatten(tolerance)
+
+			assert.True(t, ring.IsClosed())
+			assert.Equal(t, tt.contour.Start, ring[0])
+			assert.InDelta(t, tt.area, ring.Area(), ring.Length()*tolerance)
+		})
+	}
+}
+
+func TestArcSegment_FlattenTolerance(t *testing.T) {
+	const tolerance = 0.001
+
+	ring := NewCircleContour(0, 0, 10).Flatten(tolerance)
+	for i := 0; i < len(ring)-1; i++ {
+		assert.InDelta(t, 10, ring[i].Distance(NewPoint(0, 0)), epsilon)
+		middle := interpolate(ring[i], ring[i+1], 0.5)
+		assert.LessOrEqual(t, 10-middle.Distance(NewPoint(0, 0)), tolerance+epsilon)
+	}
+}
+
+func TestArcSegment_Transform(t *testing.T) {
+	arc := ArcSegment{Center: NewPoint(1, 0), To: NewPoint(2, 0), Clockwise: true}
+
+	moved := arc.Transform(func(pt Point) Point { return pt.Offset(NewPoint(1, 1)) })
+	assert.Equal(t, ArcSegment{Center: NewPoint(2, 1), To: NewPoint(3, 1), Clockwise: true}, moved)
+
+	transposed := arc.Transform(func(pt Point) Point { return NewPoint(pt.Y, pt.X) })
+	assert.Equal(t, ArcSegment{Center: NewPoint(0, 1), To: NewPoint(0, 2), Clockwise: false}, transposed)
+}
+
+func TestSVGDrawer_AddShape(t *testing.T) {
+	d := NewSVGDrawer()
+	d.AddShape(Shape{Outer: NewCircleContour(0, 0, 1)})
+
+	var buf bytes.Buffer
+	d.Write(&buf)
+	assert.True(t, strings.Contains(buf.String(),
+		`d="M-1.000000,0.000000 A1.000000,1.000000 0 0,0 1.000000,0.000000 A1.000000,1.000000 0 0,0 -1.000000,0.000000 Z"`),
+	)
+}
+
+func TestHPGLWriter_AddContour(t *testing.T) {
+	w := NewHPGLWriter(WithPlotterUnits(1))
+	w.AddContour(NewCircleContour(0, 0, 1), layerPart)
+
+	var buf bytes.Buffer
+	w.Write(&buf)
+	assert.Equal(t, "IN;\nSP1;\nPU-1,0;\nPD;AA0,0,-360.000000;\nPU;\nSP0;\n", buf.String())
+}
+
+func TestShape_FlattenCovers(t *testing.T) {
+	const tolerance = 0.5
+
+	shape := Shape{
+		Outer: Contour{
+			Start: NewPoint(0, 0),
+			Curves: []Curve{
+				LineSegment{To: NewPoint(0, 10)},
+				ArcSegment{Center: NewPoint(10, 10), To: NewPoint(20, 10), Clockwise: true},
+				LineSegment{To: NewPoint(20, 0)},
+			},
+		},
+		Inner: []Contour{NewCircleContour(10, 5, 3)},
+	}
+	poly := shape.Flatten(tolerance)
+
+	// the fine approximation of the outer contour lies within the polygon
+	assertContainsRing(t, NewPolygon(poly.outerRing), shape.Outer.Flatten(tolerance/100))
+	// and the one of the hole lies outside of it
+	for _, pt := range shape.Inner[0].Flatten(tolerance / 100) {
+		assert.False(t, NewPolygon(poly.innerRings[0]).Contains(pt), "point %v is inside the hole", pt)
+	}
+}
+
+func TestContour_StartNear(t *testing.T) {
+	// the half disc closed by the line back to the start
+	contour := Contour{
+		Start:  NewPoint(0, 0),
+		Curves: []Curve{ArcSegment{Center: NewPoint(2, 0), To: NewPoint(4, 0), Clockwise: true}},
+	}
+
+	assert.Equal(t, contour, contour.StartNear(NewPoint(-1, 0)))
+	assert.Equal(t, Contour{
+		Start: NewPoint(4, 0),
+		Curves: []Curve{
+			LineSegment{To: NewPoint(0, 0)},
+			ArcSegment{Center: NewPoint(2, 0), To: NewPoint(4, 0), Clockwise: true},
+		},
+	}, contour.StartNear(NewPoint(5, 0)))
+
+	circle := NewCircleContour(0, 0, 1)
+	assert.Equal(t, circle, circle.StartNear(NewPoint(1, 0)))
+}
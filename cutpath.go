@@ -0,0 +1,193 @@
+package main
+
+import "slices"
+
+// CutContour represents a closed contour to be cut
+type CutContour struct {
+	// the closed ring that starts and ends at the pierce point
+	Ring Ring
+	// the index of the part the contour belongs to
+	Part int
+	// the number of the ring in the part: 0 is the outer ring, i is the inner ring i-1
+	RingNum int
+	Hole    bool
+	// the sections of the contour cut by the contours of other parts
+	Shared []Line
+}
+
+// Start returns the pierce point of the contour
+func (c CutContour) Start() Point {
+	return c.Ring[0]
+}
+
+// CutSequence represents the order in which the contours are cut
+type CutSequence struct {
+	Contours []CutContour
+	// the total length of the rapid (non-cutting) moves
+	Travel float64
+	origin Point
+}
+
+// max number of the improvement passes
+const cutSequenceMaxPasses = 10
+
+// NewCutSequence orders the contours of the placed parts so that the rapid travel
+// between them starting from the origin is minimal. The holes of a part are always
+// cut before its outer contour, otherwise the part falls out of the sheet.
+// The order is built with the nearest neighbour heuristic and then improved with 2-opt.
+func NewCutSequence(parts []Polygon, origin Point) CutSequence {
+	var contours []CutContour
+	for i, part := range parts {
+		for j, innerRing := range part.innerRings {
+			contours = append(contours, CutContour{Ring: innerRing, Part: i, RingNum: j + 1, Hole: true})
+		}
+		if len(part.outerRing) > 0 {
+			contours = append(contours, CutContour{Ring: part.outerRing, Part: i})
+		}
+	}
+
+	seq := CutSequence{origin: origin}
+	seq.Contours = nearestNeighbour(contours, origin)
+
+	for pass := 0; pass < cutSequenceMaxPasses; pass++ {
+		travel := seq.travel()
+		prev := slices.Clone(seq.Contours)
+		seq.twoOpt()
+		seq.chooseStartPoints()
+		if seq.travel() >= travel {
+			seq.Contours = prev
+			break
+		}
+	}
+
+	seq.Travel = seq.travel()
+	return seq
+}
+
+// nearestNeighbour orders the contours by repeatedly choosing the nearest contour
+// that can be cut at the moment
+func nearestNeighbour(contours []CutContour, origin Point) []CutContour {
+	// the number of uncut holes of each part
+	holes := make(map[int]int)
+	for _, c := range contours {
+		if c.Hole {
+			holes[c.Part]++
+		}
+	}
+
+	visited := make([]bool, len(contours))
+	ordered := make([]CutContour, 0, len(contours))
+	current := origin
+
+	for len(ordered) != len(contours) {
+		best, bestVertex, bestDist := -1, 0, 0.0
+		for i, c := range contours {
+			if visited[i] || !c.Hole && holes[c.Part] > 0 {
+				continue
+			}
+			vertex, dist := nearestVertex(c.Ring, current)
+			if best == -1 || dist < bestDist {
+				best, bestVertex, bestDist = i, vertex, dist
+			}
+		}
+
+		visited[best] = true
+		c := contours[best]
+		if c.Hole {
+			holes[c.Part]--
+		}
+		c.Ring = c.Ring.StartAt(bestVertex)
+		ordered = append(ordered, c)
+		current = c.Start()
+	}
+
+	return ordered
+}
+
+// nearestVertex returns the index of the ring vertex nearest to the point and the distance to it
+func nearestVertex(ring Ring, pt Point) (int, float64) {
+	points := ring
+	if ring.IsClosed() {
+		points = ring[:len(ring)-1]
+	}
+
+	best, bestDist := 0, pt.Distance(points[0])
+	for i, vertex := range points[1:] {
+		if dist := pt.Distance(vertex); dist < bestDist {
+			best, bestDist = i+1, dist
+		}
+	}
+	return best, bestDist
+}
+
+// point returns the pierce point of the contour with the given index,
+// the index -1 is the origin
+func (s *CutSequence) point(idx int) Point {
+	if idx < 0 {
+		return s.origin
+	}
+	return s.Contours[idx].Start()
+}
+
+// twoOpt reverses the parts of the sequence while it reduces the travel.
+// The pierce points are fixed, the contour starts and ends at the same point.
+func (s *CutSequence) twoOpt() {
+	n := len(s.Contours)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				before := s.point(i - 1).Distance(s.point(i))
+				after := s.point(i - 1).Distance(s.point(j))
+				if j+1 < n {
+					before += s.point(j).Distance(s.point(j + 1))
+					after += s.point(i).Distance(s.point(j + 1))
+				}
+
+				if after >= before-epsilon || !s.canReverse(i, j) {
+					continue
+				}
+
+				for l, r := i, j; l < r; l, r = l+1, r-1 {
+					s.Contours[l], s.Contours[r] = s.Contours[r], s.Contours[l]
+				}
+				improved = true
+			}
+		}
+	}
+}
+
+// canReverse returns true if reversing the contours between i and j
+// does not put an outer contour before one of its holes
+func (s *CutSequence) canReverse(i, j int) bool {
+	for k := i; k <= j; k++ {
+		if s.Contours[k].Hole {
+			continue
+		}
+		for l := i; l < k; l++ {
+			if s.Contours[l].Hole && s.Contours[l].Part == s.Contours[k].Part {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// chooseStartPoints moves the pierce point of each contour to the vertex
+// nearest to the pierce point of the previous contour
+func (s *CutSequence) chooseStartPoints() {
+	current := s.origin
+	for i := range s.Contours {
+		vertex, _ := nearestVertex(s.Contours[i].Ring, current)
+		s.Contours[i].Ring = s.Contours[i].Ring.StartAt(vertex)
+		current = s.Contours[i].Start()
+	}
+}
+
+func (s *CutSequence) travel() float64 {
+	var travel float64
+	for i := range s.Contours {
+		travel += s.point(i - 1).Distance(s.point(i))
+	}
+	return travel
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCutSequence(t *testing.T) {
+	type cut struct {
+		part  int
+		hole  bool
+		start Point
+	}
+
+	tests := []struct {
+		name     string
+		parts    []Polygon
+		expected []cut
+		travel   float64
+	}{
+		{
+			name: "hole before outer contour",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 10, 10), NewRectangle(4, 4, 2, 2)),
+			},
+			expected: []cut{
+				{part: 0, hole: true, start: NewPoint(4, 4)},
+				{part: 0, start: NewPoint(0, 0)},
+			},
+			travel: 2 * NewPoint(0, 0).Distance(NewPoint(4, 4)),
+		},
+		{
+			name: "nearest part first",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 1, 1)),
+				NewPolygon(NewRectangle(10, 0, 1, 1)),
+				NewPolygon(NewRectangle(5, 0, 1, 1)),
+			},
+			expected: []cut{
+				{part: 0, start: NewPoint(0, 0)},
+				{part: 2, start: NewPoint(5, 0)},
+				{part: 1, start: NewPoint(10, 0)},
+			},
+			travel: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCutSequence(tt.parts, NewPoint(0, 0))
+
+			cuts := make([]cut, len(got.Contours))
+			for i, c := range got.Contours {
+				assert.True(t, c.Ring.IsClosed())
+				cuts[i] = cut{part: c.Part, hole: c.Hole, start: c.Start()}
+			}
+			assert.Equal(t, tt.expected, cuts)
+			assert.InDelta(t, tt.travel, got.Travel, epsilon)
+		})
+	}
+}
+
+func TestCutSequence_TwoOpt(t *testing.T) {
+	seq := CutSequence{
+		Contours: []CutContour{
+			{Ring: NewRectangle(3, 0, 1, 1), Part: 0},
+			{Ring: NewRectangle(1, 0, 1, 1), Part: 1},
+			{Ring: NewRectangle(2, 0, 1, 1), Part: 2},
+		},
+	}
+
+	seq.twoOpt()
+
+	var parts []int
+	for _, c := range seq.Contours {
+		parts = append(parts, c.Part)
+	}
+	assert.Equal(t, []int{1, 2, 0}, parts)
+	assert.InDelta(t, 3, seq.travel(), epsilon)
+}
@@ -2,9 +2,17 @@ package main
 
 import (
 	"cmp"
+	"math"
 	"slices"
 )
 
+const (
+	// the vertical strips, the sheet grows along x
+	stripsVertical = "vertical"
+	// the horizontal strips, the sheet grows along y
+	stripsHorizontal = "horizontal"
+)
+
 // Strip represents the collection of ranges in a strip
 // For example, a strip of square with height 2 has a segment [(0,2)]
 // If piece has holes, then segment will contain multiple ranges
@@ -27,6 +35,45 @@ func (s Strip) End() float64 {
 	return maxEnd
 }
 
+// Free returns the strip with the range added and merged with the touching ranges
+func (s Strip) Free(rng Range) Strip {
+	ranges := append(slices.Clone(s), rng)
+	slices.SortFunc(ranges, func(a, b Range) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	merged := Strip{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End+epsilon {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Complement returns the ranges between start and end not covered by the strip
+func (s Strip) Complement(start, end float64) Strip {
+	ranges := slices.Clone(s)
+	slices.SortFunc(ranges, func(a, b Range) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	complement := Strip{}
+	for _, r := range ranges {
+		if r.Start > start+epsilon && end > start+epsilon {
+			complement = append(complement, Range{Start: start, End: min(r.Start, end)})
+		}
+		start = max(start, r.End)
+	}
+	if end > start+epsilon {
+		complement = append(complement, Range{Start: start, End: end})
+	}
+	return complement
+}
+
 // OccupancyTable represents the part occupancy table
 type OccupancyTable []Strip
 
@@ -49,66 +96,106 @@ func NewRectanlePart(height, width int) OccupancyTable {
 }
 
 // Discretize decomposes the polygon into a number of vertical strips of the same width
-// and the part occupancy is designated by the range of part on each vertical strip
+// and the part occupancy is designated by the range of part on each vertical strip.
+// The ranges are the exact projection of the part within the strip rounded outwards,
+// so the ranges always cover the part.
 func Discretize(poly Polygon, step float64) OccupancyTable {
-	var strips []Strip
-
-	minx, _, maxx, _ := poly.Bounds()
-	l := poly.Intersections(minx)
-
-	for i := minx + step; i < maxx+step; i = i + step {
-		r := poly.Intersections(i)
+	minx, _, _, _ := poly.Bounds()
+	return discretizeFrom(poly, minx, step)
+}
 
-		outerRange := findOccupancyRange(poly.outerRing, l.Outer, r.Outer, i, step)
+// discretizeFrom decomposes the polygon into the vertical strips starting at x0
+func discretizeFrom(poly Polygon, x0, step float64) OccupancyTable {
+	_, _, maxx, _ := poly.Bounds()
+	strips := make([]Strip, max(1, int(math.Ceil((maxx-x0)/step-epsilon))))
+	for i := range strips {
+		x := x0 + float64(i)*step
+		strips[i] = stripOccupancy(poly, x, x+step)
+	}
+	return OccupancyTable(strips)
+}
 
-		var inners []Range
+// DiscretizeHorizontal decomposes the polygon into a number of horizontal strips
+// of the same height going upwards and the part occupancy is designated by the range
+// of part along x on each horizontal strip
+func DiscretizeHorizontal(poly Polygon, step float64) OccupancyTable {
+	return Discretize(poly.Transpose(), step)
+}
 
-		for _, inner := range poly.innerRings {
-			ri := inner.Intersections(i)
-			li := inner.Intersections(i - step)
-			if len(ri) == 0 || len(li) == 0 {
+// stripOccupancy returns the ranges of y occupied by the polygon between x0 and x1.
+// The projection may change only at the y of the vertices inside the strip and
+// of the edges at the strip boundaries, so it is enough to probe a line
+// between each pair of these critical values.
+func stripOccupancy(poly Polygon, x0, x1 float64) Strip {
+	var ys []float64
+	for _, ring := range poly.rings() {
+		for i := 0; i < len(ring)-1; i++ {
+			a, b := ring[i], ring[i+1]
+			if max(a.X, b.X) < x0 || min(a.X, b.X) > x1 {
 				continue
 			}
-			innerRange := findOccupancyRange(inner, li, ri, i, step)
-			inners = append(inners, innerRange)
+			if a.X == b.X {
+				ys = append(ys, a.Y, b.Y)
+				continue
+			}
+			// the end points of the edge clipped by the strip
+			l := Line{Start: a, End: b}
+			ys = append(ys, l.y(max(x0, min(a.X, b.X))), l.y(min(x1, max(a.X, b.X))))
 		}
+	}
+	slices.Sort(ys)
+	ys = slices.Compact(ys)
 
-		strips = append(strips, outerRange.Split(inners))
-
-		l = r
+	if len(ys) == 1 {
+		return Strip{NewRange(floorTo(ys[0], 4), ceilTo(ys[0], 4))}
 	}
 
-	return OccupancyTable(strips)
+	var strip Strip
+	for i := 0; i < len(ys)-1; i++ {
+		if !lineHitsMaterial(poly, x0, x1, (ys[i]+ys[i+1])/2) {
+			continue
+		}
+		rng := NewRange(floorTo(ys[i], 4), ceilTo(ys[i+1], 4))
+		if len(strip) > 0 && strip[len(strip)-1].End >= rng.Start {
+			strip[len(strip)-1].End = rng.End
+			continue
+		}
+		strip = append(strip, rng)
+	}
+	return strip
 }
 
-func findOccupancyRange(ring Ring, l, r []Point, i float64, step float64) Range {
-	findVerticesBetween := func(x1, x2 float64) []float64 {
-		var vertices []float64
-		for _, point := range ring {
-			if point.X > x1 && point.X < x2 {
-				vertices = append(vertices, point.Y)
+// lineHitsMaterial returns true if the horizontal line at y between x0 and x1
+// passes through the material of the polygon
+func lineHitsMaterial(poly Polygon, x0, x1, y float64) bool {
+	var xs []float64
+	for _, ring := range poly.rings() {
+		for i := 0; i < len(ring)-1; i++ {
+			a, b := ring[i], ring[i+1]
+			if (a.Y > y) != (b.Y > y) {
+				xs = append(xs, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
 			}
 		}
-
-		return vertices
 	}
+	slices.Sort(xs)
 
-	y1 := slices.MinFunc(append(l, r...), func(a, b Point) int {
-		return cmp.Compare(a.Y, b.Y)
-	})
-
-	y2 := slices.MaxFunc(append(l, r...), func(a, b Point) int {
-		return cmp.Compare(a.Y, b.Y)
-	})
-
-	ymin, ymax := y1.Y, y2.Y
-
-	// a convex figure may have a vertex between intersections
-	vertices := findVerticesBetween(float64(i-step), float64(i))
-	if len(vertices) > 0 {
-		ymin = min(ymin, slices.Min(vertices))
-		ymax = max(ymax, slices.Max(vertices))
+	// the line enters and leaves the material at the crossings in turn
+	for i := 0; i+1 < len(xs); i += 2 {
+		if xs[i] < x1 && xs[i+1] > x0 {
+			return true
+		}
 	}
+	return false
+}
+
+// floorTo rounds the number down to the precision
+func floorTo(num float64, precision int) float64 {
+	output := math.Pow(10, float64(precision))
+	return math.Floor(num*output+epsilon) / output
+}
 
-	return NewRange(toFixed(ymin, 4), toFixed(ymax, 4))
+// ceilTo rounds the number up to the precision
+func ceilTo(num float64, precision int) float64 {
+	output := math.Pow(10, float64(precision))
+	return math.Ceil(num*output-epsilon) / output
 }
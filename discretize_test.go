@@ -1,6 +1,7 @@
 package main
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +50,190 @@ func TestDescritizate(t *testing.T) {
 		})
 	}
 }
+
+func TestDiscretizeHorizontal(t *testing.T) {
+	tests := []struct {
+		name     string
+		poly     Polygon
+		step     float64
+		expected OccupancyTable
+	}{
+		{
+			name:     "rectangle",
+			poly:     NewPolygon(Ring{{0, 0}, {0, 1}, {2, 1}, {2, 0}, {0, 0}}),
+			step:     0.5,
+			expected: OccupancyTable{{{0, 2}}, {{0, 2}}},
+		},
+		{
+			name:     "triangle",
+			poly:     NewPolygon(Ring{{0, 0}, {3, 1}, {0, 4}, {0, 0}}),
+			step:     2,
+			expected: OccupancyTable{{{0, 3}}, {{0, 2}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiscretizeHorizontal(tt.poly, tt.step)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+// stripCovers returns true if the point lies inside one of the ranges of its strip
+func stripCovers(table OccupancyTable, minx, step float64, pt Point) bool {
+	i := int((pt.X - minx) / step)
+	for _, strip := range []int{i - 1, i, i + 1} {
+		// the point on the strip boundary belongs to both strips
+		if strip < 0 || strip >= len(table) ||
+			pt.X < minx+float64(strip)*step-epsilon || pt.X > minx+float64(strip+1)*step+epsilon {
+			continue
+		}
+		for _, rng := range table[strip] {
+			if rng.Start-epsilon <= pt.Y && pt.Y <= rng.End+epsilon {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestDiscretize_Covers(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	polys := []Polygon{
+		// the vertical edges lie on the strip boundaries
+		NewPolygon(NewRectangle(0, 0, 4, 4), NewRectangle(1, 1, 2, 2)),
+		// the hole touches only one side of the strip
+		NewPolygon(NewRectangle(0, 0, 6, 6), NewRectangle(1, 1, 4, 3)),
+		// the concave part
+		NewPolygon(Ring{{0, 0}, {0, 4}, {1, 4}, {1, 1}, {3, 1}, {3, 4}, {4, 4}, {4, 0}, {0, 0}}),
+	}
+	for i := 0; i < 50; i++ {
+		poly := randomPolygon(rnd, NewPoint(5, 5))
+		if i%2 == 0 {
+			// the hole around the center of the star polygon
+			poly.innerRings = []Ring{NewRectangle(4.5, 4.5, 0.5+rnd.Float64()*0.4, 0.5+rnd.Float64()*0.4)}
+		}
+		polys = append(polys, poly)
+	}
+
+	for i, poly := range polys {
+		for _, step := range []float64{0.3, 1, 2} {
+			table := Discretize(poly, step)
+			minx, miny, maxx, maxy := poly.Bounds()
+
+			for n := 0; n < 500; n++ {
+				pt := NewPoint(minx+rnd.Float64()*(maxx-minx), miny+rnd.Float64()*(maxy-miny))
+				if !poly.Contains(pt) {
+					continue
+				}
+				assert.True(t, stripCovers(table, minx, step, pt), "polygon %d, step %f, point %v", i, step, pt)
+			}
+
+			// the vertices lie on the boundary of the material
+			for _, ring := range poly.rings() {
+				for _, pt := range ring {
+					assert.True(t, stripCovers(table, minx, step, pt), "polygon %d, step %f, vertex %v", i, step, pt)
+				}
+			}
+		}
+	}
+}
+
+func TestDiscretize_Holes(t *testing.T) {
+	tests := []struct {
+		name     string
+		poly     Polygon
+		step     float64
+		expected OccupancyTable
+	}{
+		{
+			name:     "hole touching one side of the strip",
+			poly:     NewPolygon(NewRectangle(0, 0, 6, 6), NewRectangle(1, 2, 2, 4)),
+			step:     2,
+			expected: OccupancyTable{{{0, 6}}, {{0, 2}, {4, 6}}, {{0, 6}}},
+		},
+		{
+			name:     "concave part",
+			poly:     NewPolygon(Ring{{0, 0}, {0, 4}, {1, 4}, {1, 1}, {3, 1}, {3, 4}, {4, 4}, {4, 0}, {0, 0}}),
+			step:     1,
+			expected: OccupancyTable{{{0, 4}}, {{0, 1}}, {{0, 1}}, {{0, 4}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Discretize(tt.poly, tt.step))
+		})
+	}
+}
+
+func TestStrip_Free(t *testing.T) {
+	tests := []struct {
+		name     string
+		strip    Strip
+		rng      Range
+		expected Strip
+	}{
+		{
+			name:     "empty strip",
+			strip:    Strip{},
+			rng:      Range{Start: 1, End: 2},
+			expected: Strip{{Start: 1, End: 2}},
+		},
+		{
+			name:     "separate range",
+			strip:    Strip{{Start: 0, End: 1}, {Start: 4, End: 5}},
+			rng:      Range{Start: 2, End: 3},
+			expected: Strip{{Start: 0, End: 1}, {Start: 2, End: 3}, {Start: 4, End: 5}},
+		},
+		{
+			name:     "range touching both neighbours",
+			strip:    Strip{{Start: 0, End: 1}, {Start: 3, End: 5}},
+			rng:      Range{Start: 1, End: 3},
+			expected: Strip{{Start: 0, End: 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.strip.Free(tt.rng))
+		})
+	}
+}
+
+func TestStrip_Complement(t *testing.T) {
+	tests := []struct {
+		name     string
+		strip    Strip
+		expected Strip
+	}{
+		{
+			name:     "empty strip",
+			strip:    Strip{},
+			expected: Strip{{Start: 0, End: 4}},
+		},
+		{
+			name:     "ranges inside",
+			strip:    Strip{{Start: 1, End: 2}, {Start: 3, End: 3.5}},
+			expected: Strip{{Start: 0, End: 1}, {Start: 2, End: 3}, {Start: 3.5, End: 4}},
+		},
+		{
+			name:     "ranges touching the ends",
+			strip:    Strip{{Start: 2, End: 4}, {Start: 0, End: 1}},
+			expected: Strip{{Start: 1, End: 2}},
+		},
+		{
+			name:     "full strip",
+			strip:    Strip{{Start: 0, End: 4}},
+			expected: Strip{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.strip.Complement(0, 4))
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
 
 type Nesting struct {
 	Problem  Problem    `xml:"problem"`
@@ -14,10 +18,10 @@ type Problem struct {
 }
 
 type Piece struct {
-	XMLName   xml.Name  `xml:"piece"`
-	ID        string    `xml:"id,attr"`
-	Quantity  int       `xml:"quantity,attr"`
-	Component Component `xml:"component"`
+	XMLName    xml.Name    `xml:"piece"`
+	ID         string      `xml:"id,attr"`
+	Quantity   int         `xml:"quantity,attr"`
+	Components []Component `xml:"component"`
 }
 
 type Component struct {
@@ -50,30 +54,92 @@ func (n *NPolygon) ToGeomPolygon() Polygon {
 	return Polygon{outer, nil}
 }
 
+// ToShape returns the shape bounded by the segments and true if any segment is a curve
+func (n *NPolygon) ToShape() (Shape, bool, error) {
+	if n.N <= 0 || n.N > len(n.Lines.Segment) {
+		return Shape{}, false, fmt.Errorf("polygon %q has %d segments of %d vertices", n.ID, len(n.Lines.Segment), n.N)
+	}
+	segments := n.Lines.Segment[:n.N]
+	outer := Contour{Start: NewPoint(segments[0].X0, segments[0].Y0)}
+	curved := false
+	for _, segment := range segments {
+		outer.Curves = append(outer.Curves, segment.curve())
+		curved = curved || segment.Curve != ""
+	}
+	return Shape{Outer: outer}, curved, nil
+}
+
+// the curves of the segments besides the straight lines
+const (
+	segmentArc   = "arc"
+	segmentQuad  = "quad"
+	segmentCubic = "cubic"
+)
+
+// Segment is the line from (x0, y0) to (x1, y1). The curve attribute turns it into
+// the arc around (xc, yc) or the Bezier curve with the control points (xc, yc) and (xc2, yc2).
 type Segment struct {
-	N  int     `xml:"n,attr"`
-	X0 float64 `xml:"x0,attr"`
-	X1 float64 `xml:"x1,attr"`
-	Y0 float64 `xml:"y0,attr"`
-	Y1 float64 `xml:"y1,attr"`
+	N         int     `xml:"n,attr"`
+	X0        float64 `xml:"x0,attr"`
+	X1        float64 `xml:"x1,attr"`
+	Y0        float64 `xml:"y0,attr"`
+	Y1        float64 `xml:"y1,attr"`
+	Curve     string  `xml:"curve,attr"`
+	XC        float64 `xml:"xc,attr"`
+	YC        float64 `xml:"yc,attr"`
+	XC2       float64 `xml:"xc2,attr"`
+	YC2       float64 `xml:"yc2,attr"`
+	Clockwise bool    `xml:"clockwise,attr"`
 }
 
-func (n *Nesting) GetBoardSizes() (float32, float32) {
+// curve returns the curve of the segment starting at the end of the previous one
+func (s Segment) curve() Curve {
+	to := NewPoint(s.X1, s.Y1)
+	switch s.Curve {
+	case segmentArc:
+		return ArcSegment{Center: NewPoint(s.XC, s.YC), To: to, Clockwise: s.Clockwise}
+	case segmentQuad:
+		return QuadBezier{Control: NewPoint(s.XC, s.YC), To: to}
+	case segmentCubic:
+		return CubicBezier{Control1: NewPoint(s.XC, s.YC), Control2: NewPoint(s.XC2, s.YC2), To: to}
+	default:
+		return LineSegment{To: to}
+	}
+}
+
+// GetBoardSizes returns the width and the height of the first board
+func (n *Nesting) GetBoardSizes() (float32, float32, error) {
 	if len(n.Problem.Boards) == 0 {
-		panic("no board in nesting")
+		return 0, 0, errors.New("no board in nesting")
+	}
+	board := n.Problem.Boards[0]
+	if len(board.Components) == 0 {
+		return 0, 0, fmt.Errorf("board %q has no component", board.ID)
 	}
 
-	polyid := n.Problem.Boards[0].Component.IDPolygon
+	polyid := board.Components[0].IDPolygon
 	for _, polygon := range n.Polygons {
 		if polygon.ID == polyid {
-			return polygon.XMax - polygon.XMin, polygon.YMax - polygon.YMin
+			return polygon.XMax - polygon.XMin, polygon.YMax - polygon.YMin, nil
 		}
 	}
-	panic("board not found")
+	return 0, 0, fmt.Errorf("polygon %q of board %q not found", polyid, board.ID)
 }
 
 func (n *Nesting) GetParts() []Polygon {
-	var parts []Polygon
+	parts, _, _, _ := n.GetPieces()
+	return parts
+}
+
+// GetPieces returns the parts, their curves and the ids of the pieces they are made of.
+// The parts with the curves are flattened to cover the curves. The curves are nil for
+// the polygons and for the pieces of several components, which are flattened before the union.
+func (n *Nesting) GetPieces() ([]Polygon, []*Shape, []string, error) {
+	var (
+		parts  []Polygon
+		shapes []*Shape
+		ids    []string
+	)
 
 	polygons := make(map[string]NPolygon)
 
@@ -82,15 +148,42 @@ func (n *Nesting) GetParts() []Polygon {
 	}
 
 	for _, lot := range n.Problem.Lot {
-		pieces := make([]Polygon, lot.Quantity)
-
-		npoly := polygons[lot.Component.IDPolygon]
+		// a piece of several components is their union,
+		// the disjoint components become separate parts
+		var (
+			components []Polygon
+			curves     *Shape
+		)
+		for _, component := range lot.Components {
+			npoly := polygons[component.IDPolygon]
+			offset := NewPoint(float64(component.XOffset), float64(component.YOffset))
+			shape, curved, err := npoly.ToShape()
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("piece %q: %w", lot.ID, err)
+			}
+			if !curved {
+				components = append(components, npoly.ToGeomPolygon().Offset(offset))
+				continue
+			}
+			shape = shape.Transform(func(pt Point) Point {
+				return pt.Offset(offset)
+			})
+			components = append(components, shape.Flatten(defaultFlattenTolerance))
+			curves = &shape
+		}
+		if len(components) > 1 {
+			components = UnionAll(components)
+			curves = nil
+		}
 
 		for i := 0; i < lot.Quantity; i++ {
-			pieces[i] = npoly.ToGeomPolygon()
+			parts = append(parts, components...)
+			for range components {
+				shapes = append(shapes, curves)
+				ids = append(ids, lot.ID)
+			}
 		}
-		parts = append(parts, pieces...)
 	}
 
-	return parts
+	return parts, shapes, ids, nil
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNesting_GetBoardSizes(t *testing.T) {
+	board := NPolygon{ID: "polygon0", XMin: 1, XMax: 11, YMin: 2, YMax: 7}
+
+	tests := []struct {
+		name   string
+		boards []Piece
+		width  float32
+		height float32
+		err    string
+	}{
+		{
+			name:   "board",
+			boards: []Piece{{ID: "board0", Components: []Component{{IDPolygon: "polygon0"}}}},
+			width:  10,
+			height: 5,
+		},
+		{
+			name: "no board",
+			err:  "no board in nesting",
+		},
+		{
+			name:   "board without component",
+			boards: []Piece{{ID: "board0"}},
+			err:    `board "board0" has no component`,
+		},
+		{
+			name:   "unknown polygon",
+			boards: []Piece{{ID: "board0", Components: []Component{{IDPolygon: "polygon1"}}}},
+			err:    `polygon "polygon1" of board "board0" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nesting := Nesting{Problem: Problem{Boards: tt.boards}, Polygons: []NPolygon{board}}
+			width, height, err := nesting.GetBoardSizes()
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.width, width)
+			assert.Equal(t, tt.height, height)
+		})
+	}
+}
+
+func TestNesting_GetPiecesWithoutSegments(t *testing.T) {
+	nesting := Nesting{
+		Problem:  Problem{Lot: []Piece{{ID: "piece0", Quantity: 1, Components: []Component{{IDPolygon: "polygon1"}}}}},
+		Polygons: []NPolygon{{ID: "polygon1"}},
+	}
+
+	_, _, _, err := nesting.GetPieces()
+	assert.ErrorContains(t, err, `piece "piece0": polygon "polygon1" has 0 segments of 0 vertices`)
+
+	_, _, _, err = prepareParts(&nesting)
+	assert.Error(t, err)
+}
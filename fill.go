@@ -2,9 +2,39 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 )
 
+// Placer places a sequence of parts in a sheet
+type Placer interface {
+	// Run sets the offsets and the orientations of the parts
+	Run(parts []*Part)
+	// Length returns the length of the sheet occupied by the placed parts
+	Length() float64
+	// Lock places the part at the position on the sheet before the other parts,
+	// it returns an error if the part overlaps the locked parts or leaves the sheet
+	Lock(part *Part, position Point) error
+	// Add places the part if it fits into the sheet and returns true if it is placed
+	Add(part *Part) bool
+	// AddWithin places the part if it fits into the first length of the sheet
+	// and returns true if it is placed
+	AddWithin(part *Part, length float64) bool
+}
+
+// the max number of the passes of the compaction
+const compactionPasses = 5
+
+// Compactor moves the placed parts closer to the bottom-left corner
+// without changing the order of the parts
+type Compactor interface {
+	// Compact re-places each part if it fits further left or lower,
+	// trying the other orientations of the part if rotate is true.
+	// It returns the number of the moves.
+	Compact(rotate bool) int
+}
+
 // BottomLeftFill implements the Bottom-Left-Fill algorithm for
 // placing a sequence of parts in a sheet
 type BottomLeftFill struct {
@@ -12,32 +42,291 @@ type BottomLeftFill struct {
 	height float32
 	// the maximum length of the sheet
 	maxLength int
+	// the width of the strip
+	step float64
+	// true if the strips are horizontal, then the height is the width of the sheet
+	// and the sheet grows along y
+	horizontal bool
+	// ranks the positions of the orientations of the part
+	rule PlacementRule
+	// the outline of the bounded sheet, nil if the sheet grows
+	outline Ring
+	// the number of the columns of the bounded sheet
+	columns int
 	// represents a table of strips
 	vacancyTable map[int]Strip
+	placed       []*Part
+	// the offsets of the placed parts in the strips
+	offsets []Offset
+	// the parts locked at their positions, they are never moved
+	locked []*Part
+	// true if the locked parts may overlap each other and the end of the sheet
+	overlappingLocks bool
+}
+
+type BottomLeftFillOption func(*BottomLeftFill)
+
+// WithStep sets the width of the strips the parts are discretized with
+func WithStep(step float64) BottomLeftFillOption {
+	return func(r *BottomLeftFill) {
+		r.step = step
+	}
+}
+
+// WithHorizontalStrips places the parts discretized into the horizontal strips
+func WithHorizontalStrips() BottomLeftFillOption {
+	return func(r *BottomLeftFill) {
+		r.horizontal = true
+	}
+}
+
+// WithPlacementRule ranks the positions of the orientations by the rule
+func WithPlacementRule(rule PlacementRule) BottomLeftFillOption {
+	return func(r *BottomLeftFill) {
+		r.rule = rule
+	}
+}
+
+// WithSheetOutline bounds the sheet by the outline, e.g. a remnant of the previous sheet.
+// The outline starts at the origin and the parts are placed only inside it.
+func WithSheetOutline(outline Ring) BottomLeftFillOption {
+	return func(r *BottomLeftFill) {
+		r.outline = outline
+	}
+}
+
+// WithOverlappingLocks lets the locked parts overlap each other and the end of the sheet.
+// The coarse strips round the locked parts outward, so the parts touching
+// at the fine strips overlap at the coarse ones.
+func WithOverlappingLocks() BottomLeftFillOption {
+	return func(r *BottomLeftFill) {
+		r.overlappingLocks = true
+	}
 }
 
 // TODO: pass maxLength as option
-func NewBottomLeftFill(height float32, maxLength int) *BottomLeftFill {
-	return &BottomLeftFill{
+func NewBottomLeftFill(height float32, maxLength int, options ...BottomLeftFillOption) *BottomLeftFill {
+	fill := &BottomLeftFill{
 		height:       height,
 		maxLength:    maxLength,
+		step:         1,
+		rule:         BottomLeftRule{},
 		vacancyTable: make(map[int]Strip),
 	}
+
+	for _, option := range options {
+		option(fill)
+	}
+
+	if fill.outline != nil {
+		fill.bound()
+	}
+
+	return fill
+}
+
+// bound fills the vacancy table with the area inside the outline. The material outside
+// the outline is discretized as the parts are, so the vacancy never exceeds the outline.
+func (r *BottomLeftFill) bound() {
+	minx, miny, maxx, maxy := NewPolygon(r.outline).Bounds()
+	outside := NewPolygon(NewRectangle(minx, miny, maxy-miny, maxx-minx), r.outline)
+
+	occupancy := Discretize(outside, r.step)
+	if r.horizontal {
+		occupancy = DiscretizeHorizontal(outside, r.step)
+	}
+
+	r.columns = len(occupancy)
+	for column, strip := range occupancy {
+		r.vacancyTable[column] = strip.Complement(0, float64(r.height))
+	}
+}
+
+func (r *BottomLeftFill) Add(part *Part) bool {
+	return r.add(part, r.maxLength)
+}
+
+func (r *BottomLeftFill) AddWithin(part *Part, length float64) bool {
+	return r.add(part, int(length/r.step+epsilon))
+}
+
+// add places the part if it fits into the first columns of the sheet
+func (r *BottomLeftFill) add(part *Part, columns int) bool {
+	proj, ok := r.place(part, columns)
+	if !ok {
+		return false
+	}
+	r.setOffset(part, proj)
+	r.placed = append(r.placed, part)
+	r.offsets = append(r.offsets, proj.offset)
+	return true
 }
 
 // Run runs the Bottom-Left-Fill algorithm and returns a list of points
 // representing the placement of the parts.
 func (r *BottomLeftFill) Run(parts []*Part) {
 	for _, part := range parts {
-		proj := r.place(part)
-		part.Offset = proj.offset
-		part.BestOrientationNum = proj.orderNum
+		if !r.Add(part) {
+			panic(fmt.Sprintf("all parts cannot be placed, column %d reached", r.maxLength))
+		}
 	}
 }
 
+func (r *BottomLeftFill) setOffset(part *Part, proj projection) {
+	part.Offset = proj.offset
+	if r.horizontal {
+		// the columns of the horizontal strips are the rows of the sheet
+		part.Offset = Offset{x: proj.offset.y, y: float64(proj.offset.column) * r.step}
+	}
+	part.BestOrientationNum = proj.orderNum
+}
+
+func (r *BottomLeftFill) Compact(rotate bool) int {
+	moves := 0
+	for pass := 0; pass < compactionPasses; pass++ {
+		moved := false
+		for i, part := range r.placed {
+			current := projection{offset: r.offsets[i], orderNum: part.BestOrientationNum}
+			r.free(part.bestOrienation().occupancy, current.offset)
+
+			best, ok := current, false
+			for num, o := range part.Orientations {
+				if num != current.orderNum && !rotate {
+					continue
+				}
+				proj, placed := r.placeOrientation(o.occupancy, part.Repeat, Offset{})
+				proj.orderNum = num
+				if placed && before(proj.offset, best.offset) {
+					best, ok = proj, true
+				}
+			}
+
+			if !ok {
+				r.occupy(part.bestOrienation().occupancy, current.offset)
+				continue
+			}
+
+			r.insert(best)
+			r.setOffset(part, best)
+			r.offsets[i] = best.offset
+			moved = true
+			moves++
+		}
+		if !moved {
+			break
+		}
+	}
+	return moves
+}
+
+// before returns true if the offset is to the left of the other or lower in the same column
+func before(offset, other Offset) bool {
+	if offset.column != other.column {
+		return offset.column < other.column
+	}
+	return offset.y < other.y-epsilon
+}
+
+// repeatAxes returns the repeats of the pattern along the sheet and across it
+func (r *BottomLeftFill) repeatAxes(repeat *Repeat) (along, across repeatAxis) {
+	x, y := repeat.axes()
+	if r.horizontal {
+		return y, x
+	}
+	return x, y
+}
+
+func (r *BottomLeftFill) Lock(part *Part, position Point) error {
+	shape := part.bestOrienation().shape.Offset(position)
+	along, across := position.X, position.Y
+	if r.horizontal {
+		// the strips go along x, so the shape is discretized transposed
+		shape = shape.Transpose()
+		along, across = across, along
+	}
+	if along < -epsilon || across < -epsilon {
+		return fmt.Errorf("locked part at %v is outside the sheet", position)
+	}
+
+	column := int(math.Floor(along/r.step + epsilon))
+	occupancy := discretizeFrom(shape, float64(column)*r.step, r.step)
+	switch {
+	case r.overlappingLocks:
+		r.cover(occupancy, Offset{column: column})
+	case column+len(occupancy) > r.maxLength:
+		return fmt.Errorf("locked part at %v is beyond the max length of the sheet", position)
+	case !r.occupy(occupancy, Offset{column: column}):
+		return fmt.Errorf("locked part at %v overlaps the locked parts or leaves the sheet", position)
+	}
+
+	part.Offset = Offset{x: position.X, y: position.Y}
+	r.locked = append(r.locked, part)
+	return nil
+}
+
+// free returns the ranges of the part at the offset to the vacancy table
+func (r *BottomLeftFill) free(part OccupancyTable, offset Offset) {
+	for stripNum, strip := range part {
+		column := offset.column + stripNum
+		for _, rng := range strip {
+			r.vacancyTable[column] = r.getVacancyStrip(column).Free(rng.Add(offset.y))
+		}
+	}
+}
+
+// occupy removes the ranges of the part at the offset from the vacancy table,
+// it returns false and keeps the table if the ranges are not vacant
+func (r *BottomLeftFill) occupy(part OccupancyTable, offset Offset) bool {
+	proj := projection{offset: offset, val: make(map[int]map[int][]Range)}
+	for stripNum, strip := range part {
+		for _, rng := range strip {
+			vacant := slices.IndexFunc(r.getVacancyStrip(offset.column+stripNum), func(vacant Range) bool {
+				return vacant.Includes(rng.Add(offset.y))
+			})
+			if vacant == -1 {
+				return false
+			}
+			proj.insert(stripNum, vacant, rng)
+		}
+	}
+	r.insert(proj)
+	return true
+}
+
+// cover removes the ranges of the part at the offset from the vacancy table
+// whether or not they are vacant
+func (r *BottomLeftFill) cover(part OccupancyTable, offset Offset) {
+	for stripNum, strip := range part {
+		column := offset.column + stripNum
+		occupied := r.getVacancyStrip(column).Complement(0, float64(r.height))
+		for _, rng := range strip {
+			occupied = occupied.Free(rng.Add(offset.y))
+		}
+		r.vacancyTable[column] = occupied.Complement(0, float64(r.height))
+	}
+}
+
+func (r *BottomLeftFill) Length() float64 {
+	parts := append(slices.Clone(r.locked), r.placed...)
+	if !r.horizontal {
+		return float64(calculateSheetLength(parts, r.step))
+	}
+
+	var length float64
+	for _, part := range parts {
+		height := float64(len(part.bestOrienation().occupancy)) * r.step
+		length = max(length, part.Offset.y+height)
+	}
+	return length
+}
+
 func (r *BottomLeftFill) getVacancyStrip(num int) Strip {
 	col, exists := r.vacancyTable[num]
-	if !exists {
+	if !exists && r.outline != nil && num >= r.columns {
+		// there is no sheet beyond the outline
+		col = Strip{}
+		r.vacancyTable[num] = col
+	} else if !exists {
 		// The sheet vacancy is from between 0 and the height of the sheet
 		col = Strip{
 			{Start: 0, End: float64(r.height)},
@@ -50,7 +339,16 @@ func (r *BottomLeftFill) getVacancyStrip(num int) Strip {
 // insert inserts the part into the occupancy table
 func (r *BottomLeftFill) insert(proj projection) {
 	for stripNum, strip := range proj.val {
-		for intervalNum, rng := range strip {
+		// the ranges split from the last vacant ranges first,
+		// so the numbers of the preceding ranges do not shift
+		intervalNums := make([]int, 0, len(strip))
+		for intervalNum := range strip {
+			intervalNums = append(intervalNums, intervalNum)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(intervalNums)))
+
+		for _, intervalNum := range intervalNums {
+			rng := strip[intervalNum]
 			offseted := make([]Range, len(rng))
 			for i, r := range rng {
 				offseted[i] = r.Add(proj.offset.y)
@@ -63,6 +361,14 @@ func (r *BottomLeftFill) insert(proj projection) {
 type Offset struct {
 	column int
 	y      float64
+	// the offset along the sheet in addition to the columns,
+	// used by the placers not bound to the strips
+	x float64
+}
+
+// Point returns the position of the part on the sheet
+func (o Offset) Point(step float64) Point {
+	return NewPoint(float64(o.column)*step+o.x, o.y)
 }
 
 func (r *BottomLeftFill) insertStrip(stripNum int, rngNum int, strip ...Range) {
@@ -88,15 +394,25 @@ func (p projection) insert(stripNum int, rngNum int, rng Range) {
 	p.val[stripNum] = strip
 }
 
-func (r *BottomLeftFill) place(part *Part) projection {
+// place inserts the best orientation of the part ending within the columns,
+// it returns false if no orientation fits
+func (r *BottomLeftFill) place(part *Part, columns int) (projection, bool) {
 	projections := make([]projection, 0, len(part.Orientations))
+	scores := make(map[int]float64, len(part.Orientations))
 	for i, orientation := range part.Orientations {
-		projection := r.placeOrientation(orientation.occupancy, Offset{})
+		projection, ok := r.placeOrientation(orientation.occupancy, part.Repeat, Offset{})
+		if !ok || projection.offset.column+len(orientation.occupancy) > columns {
+			continue
+		}
 		projection.orderNum = i
 		projections = append(projections, projection)
+		scores[i] = r.rule.Score(r, &part.Orientations[i], projection.offset)
 	}
 
 	sort.Slice(projections, func(i, j int) bool {
+		if a, b := scores[projections[i].orderNum], scores[projections[j].orderNum]; math.Abs(a-b) > epsilon {
+			return a < b
+		}
 		if projections[i].offset.column != projections[j].offset.column {
 			return projections[i].offset.column < projections[j].offset.column
 		}
@@ -105,18 +421,31 @@ func (r *BottomLeftFill) place(part *Part) projection {
 		return projections[i].offset.y < projections[j].offset.y
 	})
 
+	if len(projections) == 0 {
+		return projection{}, false
+	}
+
 	r.insert(projections[0])
-	return projections[0]
+	return projections[0], true
 }
 
-func (r *BottomLeftFill) placeOrientation(part OccupancyTable, offset Offset) projection {
+// placeOrientation places the part at the first position from the offset allowed by the repeat,
+// it returns false if the part does not fit into the sheet
+func (r *BottomLeftFill) placeOrientation(part OccupancyTable, repeat *Repeat, offset Offset) (projection, bool) {
 	if offset.column >= r.maxLength {
-		panic(fmt.Sprintf("all parts cannot be placed, column %d reached", offset.column))
+		return projection{}, false
 	}
 
+	along, across := r.repeatAxes(repeat)
+	if column := float64(offset.column) * r.step; along.next(column) > column+epsilon {
+		// the column is off the repeat of the pattern
+		return r.placeOrientation(part, repeat, Offset{column: offset.column + 1})
+	}
+	offset.y = across.next(offset.y)
+
 	var (
-		cursor     int // the index of the placed strip
-		projection = projection{
+		cursor int // the index of the placed strip
+		proj   = projection{
 			offset: offset, // offset is the current position of the part
 			val:    make(map[int]map[int][]Range),
 		}
@@ -125,41 +454,44 @@ func (r *BottomLeftFill) placeOrientation(part OccupancyTable, offset Offset) pr
 	for cursor != len(part) {
 		strip := part[cursor]
 
-		// TODO: check it in Run
 		if strip.End() > float64(r.height) {
-			// TODO return error
-			panic("the end point of the part cannot be greater than ymax")
+			// the part is higher than the sheet
+			return projection{}, false
 		}
 
 		for _, stripRange := range strip {
-			ok, rngNum, vacantRange := r.findVacantRange(projection.offset, cursor, stripRange)
+			ok, rngNum, vacantRange := r.findVacantRange(proj.offset, cursor, stripRange)
 			if !ok {
 				// failed to place a segment of the piece, move to the next column
-				return r.placeOrientation(part, Offset{
-					column: projection.offset.column + 1,
+				return r.placeOrientation(part, repeat, Offset{
+					column: proj.offset.column + 1,
 					y:      0,
 				})
 			}
 
 			newoffset := toFixed(vacantRange.Start-stripRange.Start, 4)
-			projection.offset.y = max(projection.offset.y, newoffset)
+			proj.offset.y = max(proj.offset.y, newoffset)
 
-			for column, projectionStrip := range projection.val {
+			for column, projectionStrip := range proj.val {
 				for vacantRngNum, projectionRanges := range projectionStrip {
 					for _, projectionRange := range projectionRanges {
-						if !r.canPlace(projection.offset, column, vacantRngNum, projectionRange) {
-							return r.placeOrientation(part, projection.offset)
+						if !r.canPlace(proj.offset, column, vacantRngNum, projectionRange) {
+							return r.placeOrientation(part, repeat, proj.offset)
 						}
 					}
 				}
 			}
 
-			projection.insert(cursor, rngNum, stripRange)
+			proj.insert(cursor, rngNum, stripRange)
 		}
 		cursor++
 	}
 
-	return projection
+	if y := across.next(proj.offset.y); y > proj.offset.y+epsilon {
+		// the vacant range moved the part off the repeat of the pattern
+		return r.placeOrientation(part, repeat, Offset{column: proj.offset.column, y: y})
+	}
+	return proj, true
 }
 
 func (f *BottomLeftFill) findVacantRange(offset Offset, colOffset int, rangeToPlace Range) (bool, int, Range) {
@@ -178,15 +510,20 @@ func (r *BottomLeftFill) canPlace(offset Offset, columnOffset int, rngNum int, r
 	return vacantRng.Includes(rng.Add(offset.y))
 }
 
+// vacancy returns the vacant ranges of the first columns of the sheet
+func (r *BottomLeftFill) vacancy(columns int) OccupancyTable {
+	table := make(OccupancyTable, columns)
+	for i := range table {
+		table[i] = r.getVacancyStrip(i)
+	}
+	return table
+}
+
 func (r *BottomLeftFill) getVacancyTable() OccupancyTable {
-	table := make(OccupancyTable, len(r.vacancyTable))
-	keys := make([]int, 0, len(r.vacancyTable))
+	// the columns are not contiguous if a part is locked away from the others
+	columns := 0
 	for k := range r.vacancyTable {
-		keys = append(keys, k)
-	}
-	sort.Ints(keys)
-	for _, k := range keys {
-		table[k] = r.vacancyTable[k]
+		columns = max(columns, k+1)
 	}
-	return table
+	return r.vacancy(columns)
 }
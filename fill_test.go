@@ -2,6 +2,8 @@ package main
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestXxx(t *testing.T) {
@@ -23,7 +25,7 @@ func TestXxx(t *testing.T) {
 				NewRectanlePart(2, 2),
 				NewRectanlePart(2, 2),
 			},
-			expected: []Offset{{0, 0}, {0, 2}},
+			expected: []Offset{{column: 0, y: 0}, {column: 0, y: 2}},
 		},
 		{
 			name: "case 2",
@@ -35,7 +37,7 @@ func TestXxx(t *testing.T) {
 				NewRectanlePart(2, 2),
 				NewRectanlePart(2, 2),
 			},
-			expected: []Offset{{0, 0}, {2, 0}},
+			expected: []Offset{{column: 0, y: 0}, {column: 2, y: 0}},
 		},
 		{
 			name: "case 3",
@@ -49,7 +51,7 @@ func TestXxx(t *testing.T) {
 				NewRectanlePart(2, 2),
 				NewRectanlePart(2, 2),
 			},
-			expected: []Offset{{0, 0}, {0, 2}, {2, 0}},
+			expected: []Offset{{column: 0, y: 0}, {column: 0, y: 2}, {column: 2, y: 0}},
 		},
 		{
 			name: "case 4",
@@ -63,7 +65,7 @@ func TestXxx(t *testing.T) {
 				NewRectanlePart(2, 2),
 				NewRectanlePart(2, 2),
 			},
-			expected: []Offset{{0, 0}, {0, 2}, {2, 2}},
+			expected: []Offset{{column: 0, y: 0}, {column: 0, y: 2}, {column: 2, y: 2}},
 		},
 		{
 			name: "case 5",
@@ -89,7 +91,7 @@ func TestXxx(t *testing.T) {
 				},
 				NewRectanlePart(2, 2),
 			},
-			expected: []Offset{{0, 0}, {2, 0}},
+			expected: []Offset{{column: 0, y: 0}, {column: 2, y: 0}},
 		},
 		{
 			name: "case 6",
@@ -113,7 +115,7 @@ func TestXxx(t *testing.T) {
 				},
 			},
 			expected: []Offset{
-				{0, 0}, {0, 2},
+				{column: 0, y: 0}, {column: 0, y: 2},
 			},
 		},
 		{
@@ -134,7 +136,7 @@ func TestXxx(t *testing.T) {
 				},
 			},
 			expected: []Offset{
-				{0, 0},
+				{column: 0, y: 0},
 			},
 		},
 		{
@@ -160,7 +162,7 @@ func TestXxx(t *testing.T) {
 				},
 			},
 			expected: []Offset{
-				{0, 0}, {0, 0},
+				{column: 0, y: 0}, {column: 0, y: 0},
 			},
 		},
 	}
@@ -173,3 +175,216 @@ func TestXxx(t *testing.T) {
 		})
 	}
 }
+
+func TestBottomLeftFill_Compact(t *testing.T) {
+	newPart := func(orientations ...OccupancyTable) *Part {
+		part := &Part{}
+		for _, occupancy := range orientations {
+			part.Orientations = append(part.Orientations, orientation{occupancy: occupancy})
+		}
+		return part
+	}
+
+	tests := []struct {
+		name   string
+		height float32
+		parts  []*Part
+		// the first part is removed before the compaction
+		removeFirst bool
+		rotate      bool
+		moves       int
+		expected    []Offset
+		length      float64
+	}{
+		{
+			name:        "part moves into the gap",
+			height:      2,
+			parts:       []*Part{newPart(NewRectanlePart(2, 2)), newPart(NewRectanlePart(2, 2))},
+			removeFirst: true,
+			moves:       1,
+			expected:    []Offset{{column: 0, y: 0}},
+			length:      2,
+		},
+		{
+			name:   "parts move one after another",
+			height: 2,
+			parts: []*Part{
+				newPart(NewRectanlePart(2, 2)),
+				newPart(NewRectanlePart(2, 1)),
+				newPart(NewRectanlePart(1, 2), NewRectanlePart(2, 1)),
+			},
+			removeFirst: true,
+			moves:       2,
+			expected:    []Offset{{column: 0, y: 0}, {column: 1, y: 0}},
+			length:      3,
+		},
+		{
+			name:   "parts move with the other orientations",
+			height: 2,
+			parts: []*Part{
+				newPart(NewRectanlePart(2, 2)),
+				newPart(NewRectanlePart(2, 1)),
+				newPart(NewRectanlePart(1, 2), NewRectanlePart(2, 1)),
+			},
+			removeFirst: true,
+			rotate:      true,
+			moves:       2,
+			expected:    []Offset{{column: 0, y: 0}, {column: 1, y: 0}},
+			length:      3,
+		},
+		{
+			name:   "tight parts stay",
+			height: 2,
+			parts: []*Part{
+				newPart(NewRectanlePart(2, 2)),
+				newPart(NewRectanlePart(1, 2)),
+				newPart(NewRectanlePart(1, 2)),
+			},
+			rotate:   true,
+			expected: []Offset{{column: 0, y: 0}, {column: 2, y: 0}, {column: 2, y: 1}},
+			length:   4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill := NewBottomLeftFill(tt.height, 10)
+			fill.Run(tt.parts)
+
+			if tt.removeFirst {
+				// the first part leaves the gap as if it was never placed
+				fill.free(tt.parts[0].bestOrienation().occupancy, fill.offsets[0])
+				fill.placed, fill.offsets = fill.placed[1:], fill.offsets[1:]
+			}
+
+			assert.Equal(t, tt.moves, fill.Compact(tt.rotate))
+			offsets := make([]Offset, 0, len(fill.placed))
+			for _, part := range fill.placed {
+				offsets = append(offsets, part.Offset)
+			}
+			assert.Equal(t, tt.expected, offsets)
+			assert.Equal(t, tt.length, fill.Length())
+
+			// the vacant and the occupied ranges cover the strips
+			var area float64
+			for _, strip := range fill.vacancyTable {
+				for _, rng := range strip {
+					area += rng.Length()
+				}
+			}
+			for _, part := range fill.placed {
+				for _, strip := range part.bestOrienation().occupancy {
+					for _, rng := range strip {
+						area += rng.Length()
+					}
+				}
+			}
+			assert.Equal(t, float64(tt.height)*float64(len(fill.vacancyTable)), area)
+		})
+	}
+}
+
+func TestBottomLeftFill_Lock(t *testing.T) {
+	square := func() *Part {
+		shape := NewPolygon(NewRectangle(0, 0, 2, 2))
+		return &Part{Shape: shape, Orientations: []orientation{{shape: shape, occupancy: NewRectanlePart(2, 2)}}}
+	}
+
+	fill := NewBottomLeftFill(4, 10)
+	locked := square()
+	assert.NoError(t, fill.Lock(locked, NewPoint(1.5, 0.5)))
+	assert.Equal(t, NewPoint(1.5, 0.5), locked.Offset.Point(1))
+
+	// the locked part occupies the strips it crosses
+	assert.Equal(t, Strip{{Start: 0, End: 0.5}, {Start: 2.5, End: 4}}, fill.vacancyTable[1])
+	assert.Equal(t, Strip{{Start: 0, End: 0.5}, {Start: 2.5, End: 4}}, fill.vacancyTable[3])
+
+	assert.Error(t, fill.Lock(square(), NewPoint(3, 1)), "overlapping the locked part")
+	assert.Error(t, fill.Lock(square(), NewPoint(6, 3)), "outside the sheet")
+	assert.Error(t, fill.Lock(square(), NewPoint(9, 0)), "beyond the max length")
+
+	parts := []*Part{square(), square()}
+	fill.Run(parts)
+	assert.Equal(t, Offset{column: 4, y: 0}, parts[0].Offset)
+	assert.Equal(t, Offset{column: 4, y: 2}, parts[1].Offset)
+	assert.Equal(t, 6.0, fill.Length())
+
+	// the compaction never moves the locked part
+	fill.Compact(true)
+	assert.Equal(t, NewPoint(1.5, 0.5), locked.Offset.Point(1))
+}
+
+func TestBottomLeftFill_OverlappingLocks(t *testing.T) {
+	square := func() *Part {
+		shape := NewPolygon(NewRectangle(0, 0, 2, 2))
+		return &Part{Shape: shape, Orientations: []orientation{{shape: shape, occupancy: NewRectanlePart(2, 2)}}}
+	}
+
+	// the touching parts share the first coarse strip
+	fill := NewBottomLeftFill(4, 2, WithStep(3))
+	assert.NoError(t, fill.Lock(square(), NewPoint(0, 0)))
+	assert.Error(t, fill.Lock(square(), NewPoint(2, 0)))
+
+	fill = NewBottomLeftFill(4, 2, WithStep(3), WithOverlappingLocks())
+	assert.NoError(t, fill.Lock(square(), NewPoint(0, 0)))
+	assert.NoError(t, fill.Lock(square(), NewPoint(2, 0)))
+	assert.Equal(t, Strip{{Start: 2, End: 4}}, fill.vacancyTable[0])
+	assert.Equal(t, Strip{{Start: 2, End: 4}}, fill.vacancyTable[1])
+}
+
+func TestBottomLeftFill_AddNoFit(t *testing.T) {
+	square := func() *Part {
+		return &Part{Orientations: []orientation{{occupancy: NewRectanlePart(2, 2)}}}
+	}
+
+	fill := NewBottomLeftFill(2, 3)
+	assert.True(t, fill.Add(square()))
+	assert.False(t, fill.Add(square()), "beyond the max length")
+	assert.False(t, fill.Add(&Part{Orientations: []orientation{{occupancy: NewRectanlePart(1, 3)}}}), "higher than the sheet")
+	assert.Equal(t, 2.0, fill.Length())
+
+	assert.PanicsWithValue(t, "all parts cannot be placed, column 3 reached", func() {
+		fill.Run([]*Part{square()})
+	})
+}
+
+func TestBottomLeftFill_AddWithin(t *testing.T) {
+	fill := NewBottomLeftFill(2, 10)
+	fill.Run([]*Part{{Orientations: []orientation{{occupancy: NewRectanlePart(2, 2)}}}})
+
+	wide := &Part{Orientations: []orientation{{occupancy: NewRectanlePart(1, 3)}}}
+	assert.False(t, fill.AddWithin(wide, 4))
+	assert.Equal(t, Strip{{Start: 0, End: 2}}, fill.vacancyTable[2])
+
+	rotated := &Part{Orientations: []orientation{
+		{occupancy: NewRectanlePart(1, 3)},
+		{occupancy: NewRectanlePart(2, 1)},
+	}}
+	assert.True(t, fill.AddWithin(rotated, 4))
+	assert.Equal(t, 1, rotated.BestOrientationNum)
+	assert.Equal(t, Offset{column: 2}, rotated.Offset)
+	assert.Equal(t, 3.0, fill.Length())
+}
+
+func TestBottomLeftFill_Repeat(t *testing.T) {
+	square := func(repeat *Repeat) *Part {
+		return &Part{Orientations: []orientation{{occupancy: NewRectanlePart(2, 2)}}, Repeat: repeat}
+	}
+
+	fill := NewBottomLeftFill(10, 20)
+	fill.Run([]*Part{square(nil)})
+
+	// the vacant range starts at 2, the next stripe is at 4
+	striped := square(&Repeat{Y: 3, OffsetY: 1})
+	assert.True(t, fill.Add(striped))
+	assert.Equal(t, Offset{column: 0, y: 4}, striped.Offset)
+
+	checked := square(&Repeat{X: 5, Y: 5, OffsetX: 3, OffsetY: 3})
+	assert.True(t, fill.Add(checked))
+	assert.Equal(t, Offset{column: 3, y: 3}, checked.Offset)
+
+	// the gap below the striped part is one short of the stripe at 3
+	tolerated := square(&Repeat{Y: 5, OffsetY: 3, Tolerance: 1})
+	assert.True(t, fill.Add(tolerated))
+	assert.Equal(t, Offset{column: 0, y: 2}, tolerated.Offset)
+}
@@ -1,6 +1,9 @@
 package main
 
-import "math"
+import (
+	"math"
+	"slices"
+)
 
 // Point represents a 2D point
 type Point struct {
@@ -196,6 +199,18 @@ func (p Polygon) Scale(factor float64) Polygon {
 	return poly.Offset(NewPoint(-minx, -miny))
 }
 
+// Transpose returns the polygon reflected across the line y = x.
+// The rings are reversed to keep their direction.
+func (p Polygon) Transpose() Polygon {
+	transposed := p.Transform(func(pt Point) Point {
+		return NewPoint(pt.Y, pt.X)
+	})
+	for _, ring := range transposed.rings() {
+		slices.Reverse(ring)
+	}
+	return transposed
+}
+
 type VerticalLine = float64
 
 type Intersection struct {
@@ -272,11 +287,13 @@ func (l Line) Intersect(line VerticalLine) (Point, bool) {
 		return Point{}, false
 	}
 
-	intersectY := l.y(line)
-	if math.IsNaN(intersectY) { // TODO
-		return Point{}, false
+	if l.Start.X == l.End.X {
+		// the vertical edge lies on the line, its start is the end of the previous edge
+		return l.End, true
 	}
 
+	intersectY := l.y(line)
+
 	return Point{X: line, Y: intersectY}, true
 }
 
@@ -309,3 +326,85 @@ func NewCircle(x, y, radius float64, numPoints int) Ring {
 	points[numPoints] = points[0]
 	return points
 }
+
+// Distance returns the euclidean distance to the given point
+func (p Point) Distance(other Point) float64 {
+	return math.Hypot(p.X-other.X, p.Y-other.Y)
+}
+
+// IsClosed returns true if the last point of the ring is equal to the first one
+func (r Ring) IsClosed() bool {
+	return len(r) > 1 && r[0] == r[len(r)-1]
+}
+
+// StartAt returns a new closed ring that starts and ends at the point with the given index
+func (r Ring) StartAt(idx int) Ring {
+	points := r
+	if r.IsClosed() {
+		points = r[:len(r)-1]
+	}
+	rotated := make(Ring, 0, len(points)+1)
+	rotated = append(rotated, points[idx:]...)
+	rotated = append(rotated, points[:idx]...)
+	return append(rotated, rotated[0])
+}
+
+// Length returns the perimeter of the ring
+func (r Ring) Length() float64 {
+	var length float64
+	for i := 0; i < len(r)-1; i++ {
+		length += r[i].Distance(r[i+1])
+	}
+	return length
+}
+
+// Contains returns true if the point lies inside the ring.
+// The result for the points on the boundary is undefined.
+// https://en.wikipedia.org/wiki/Point_in_polygon#Ray_casting_algorithm
+func (r Ring) Contains(pt Point) bool {
+	inside := false
+	for i := 0; i < len(r)-1; i++ {
+		a, b := r[i], r[i+1]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) &&
+			pt.X < a.X+(pt.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Contains returns true if the point lies inside the outer ring and outside the inner rings
+func (p Polygon) Contains(pt Point) bool {
+	if !p.outerRing.Contains(pt) {
+		return false
+	}
+	for _, innerRing := range p.innerRings {
+		if innerRing.Contains(pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// Crosses returns true if the lines intersect at a single point
+// which is not an end point of any of them
+func (l Line) Crosses(other Line) bool {
+	d1 := turn(other.Start, other.End, l.Start)
+	d2 := turn(other.Start, other.End, l.End)
+	d3 := turn(l.Start, l.End, other.Start)
+	d4 := turn(l.Start, l.End, other.End)
+	return d1*d2 < 0 && d3*d4 < 0
+}
+
+// turn returns the sign of the cross product (b - a) x (c - a):
+// 1 if a, b, c turn counter-clockwise, -1 if clockwise and 0 if they are collinear
+func turn(a, b, c Point) int {
+	v := (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+	if math.Abs(v) < epsilon {
+		return 0
+	}
+	if v > 0 {
+		return 1
+	}
+	return -1
+}
@@ -34,7 +34,7 @@ func TestPolygon_Intersections(t *testing.T) {
 		name     string
 		poly     Polygon
 		line     VerticalLine
-		expected []Point
+		expected Intersection
 	}{
 		{
 			name: "case 1",
@@ -42,7 +42,7 @@ func TestPolygon_Intersections(t *testing.T) {
 				{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0},
 			}, nil),
 			line:     1,
-			expected: []Point{{1, 2}, {1, 0}},
+			expected: Intersection{Outer: []Point{{1, 2}, {1, 0}}},
 		},
 		{
 			name: "case 2",
@@ -50,7 +50,7 @@ func TestPolygon_Intersections(t *testing.T) {
 				{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0},
 			}, nil),
 			line:     0,
-			expected: []Point{{0, 2}, {0, 0}},
+			expected: Intersection{Outer: []Point{{0, 2}, {0, 0}}},
 		},
 		{
 			name: "case 3",
@@ -58,8 +58,11 @@ func TestPolygon_Intersections(t *testing.T) {
 				NewRectangle(0, 0, 4, 4),
 				NewRectangle(1, 1, 2, 2),
 			),
-			line:     2,
-			expected: []Point{{2, 4}, {2, 0}, {2, 3}, {2, 1}},
+			line: 2,
+			expected: Intersection{
+				Outer: []Point{{2, 4}, {2, 0}},
+				Inner: [][]Point{{{2, 3}, {2, 1}}},
+			},
 		},
 	}
 
@@ -116,14 +119,15 @@ func TestPolygon_Centroid(t *testing.T) {
 		{
 			name:     "triangle",
 			poly:     NewPolygon(Ring{{0, 0}, {50, 100}, {100, 0}, {0, 0}}),
-			expected: NewPoint(50, 50),
+			expected: NewPoint(50, 100.0/3),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got := tt.poly.Centroid()
-			assert.Equal(t, tt.expected, got)
+			assert.InDelta(t, tt.expected.X, got.X, epsilon)
+			assert.InDelta(t, tt.expected.Y, got.Y, epsilon)
 		})
 	}
 }
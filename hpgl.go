@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// the number of plotter units per millimetre defined by HPGL
+const defaultPlotterUnits = 40
+
+// layers are used to map the contours to the plotter pens
+const (
+	layerSheet = "sheet"
+	layerPart  = "part"
+	layerHole  = "hole"
+)
+
+// HPGLWriter renders contours as HPGL commands for cutting plotters.
+// Each contour is drawn with the pen assigned to its layer.
+type HPGLWriter struct {
+	buffer bytes.Buffer
+	units  float64        // plotter units per drawing unit
+	pens   map[string]int // layer to pen number
+	pen    int            // the selected pen, 0 if none
+}
+
+type HPGLWriterOption func(*HPGLWriter)
+
+func WithPlotterUnits(units float64) HPGLWriterOption {
+	return func(w *HPGLWriter) {
+		w.units = units
+	}
+}
+
+func WithPen(layer string, pen int) HPGLWriterOption {
+	return func(w *HPGLWriter) {
+		w.pens[layer] = pen
+	}
+}
+
+func NewHPGLWriter(opts ...HPGLWriterOption) *HPGLWriter {
+	w := &HPGLWriter{
+		units: defaultPlotterUnits,
+		pens: map[string]int{
+			layerSheet: 1,
+			layerPart:  1,
+			layerHole:  1,
+		},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.buffer.WriteString("IN;\n")
+	return w
+}
+
+// AddRing draws the ring with the pen of the given layer
+func (w *HPGLWriter) AddRing(ring Ring, layer string) {
+	// the pen must return to the start point if the ring is not closed
+	if len(ring) > 0 && !ring.IsClosed() {
+		ring = append(ring[:len(ring):len(ring)], ring[0])
+	}
+	w.AddPolyline(ring, layer)
+}
+
+// AddPolyline draws the continuous line through the points with the pen of the given layer
+func (w *HPGLWriter) AddPolyline(points []Point, layer string) {
+	if len(points) == 0 {
+		return
+	}
+
+	w.selectPen(layer)
+
+	w.buffer.WriteString("PU")
+	w.writePoint(points[0])
+	w.buffer.WriteString(";\n")
+
+	w.buffer.WriteString("PD")
+	for i, pt := range points[1:] {
+		if i > 0 {
+			w.buffer.WriteString(",")
+		}
+		w.writePoint(pt)
+	}
+	w.buffer.WriteString(";\n")
+}
+
+// AddContour draws the contour with the true arcs (AA) and Bezier curves (BZ)
+func (w *HPGLWriter) AddContour(contour Contour, layer string) {
+	w.selectPen(layer)
+
+	w.buffer.WriteString("PU")
+	w.writePoint(contour.Start)
+	w.buffer.WriteString(";\n")
+
+	current := contour.Start
+	for _, curve := range contour.Curves {
+		switch c := curve.(type) {
+		case ArcSegment:
+			// the positive angle is counter-clockwise
+			w.buffer.WriteString("PD;AA")
+			w.writePoint(c.Center)
+			w.buffer.WriteString(fmt.Sprintf(",%f;\n", c.Sweep(current)*180/math.Pi))
+		case QuadBezier:
+			w.writeBezier(c.Cubic(current))
+		case CubicBezier:
+			w.writeBezier(c)
+		default:
+			w.buffer.WriteString("PD")
+			w.writePoint(curve.End())
+			w.buffer.WriteString(";\n")
+		}
+		current = curve.End()
+	}
+
+	if current != contour.Start {
+		w.buffer.WriteString("PD")
+		w.writePoint(contour.Start)
+		w.buffer.WriteString(";\n")
+	}
+}
+
+func (w *HPGLWriter) writeBezier(c CubicBezier) {
+	w.buffer.WriteString("PD;BZ")
+	w.writePoint(c.Control1)
+	w.buffer.WriteString(",")
+	w.writePoint(c.Control2)
+	w.buffer.WriteString(",")
+	w.writePoint(c.To)
+	w.buffer.WriteString(";\n")
+}
+
+// AddPolygon draws the inner rings of the polygon and then the outer ring
+func (w *HPGLWriter) AddPolygon(poly Polygon) {
+	for _, innerRing := range poly.innerRings {
+		w.AddRing(innerRing, layerHole)
+	}
+	w.AddRing(poly.outerRing, layerPart)
+}
+
+// AddCutSequence draws the contours in the cutting order with the leads and micro-joints.
+// curves[i] is the placed shape of the part i or nil if the part is a polygon. The contours
+// without the leads, the micro-joints and the shared lines are drawn with the true curves
+// from the curve end nearest to the pierce point, the other ones are cut along their flattened rings.
+func (w *HPGLWriter) AddCutSequence(seq CutSequence, opts LeadOptions, curves []*Shape) {
+	plain := opts.Type == leadNone && opts.Tabs == 0
+	for _, contour := range seq.Contours {
+		layer := layerPart
+		if contour.Hole {
+			layer = layerHole
+		}
+		if plain && len(contour.Shared) == 0 && contour.Part < len(curves) && curves[contour.Part] != nil {
+			curve := curves[contour.Part].Contours()[contour.RingNum]
+			w.AddContour(curve.StartNear(contour.Start()), layer)
+			continue
+		}
+		for _, stroke := range NewCutPath(contour, opts).Strokes() {
+			w.AddPolyline(stroke, layer)
+		}
+	}
+}
+
+// AddSheet draws the boundary of the used part of the sheet
+func (w *HPGLWriter) AddSheet(length, height float64) {
+	w.AddRing(NewRectangle(0, 0, height, length), layerSheet)
+}
+
+func (w *HPGLWriter) selectPen(layer string) {
+	pen := w.pens[layer]
+	if pen == w.pen {
+		return
+	}
+	w.buffer.WriteString(fmt.Sprintf("SP%d;\n", pen))
+	w.pen = pen
+}
+
+func (w *HPGLWriter) writePoint(pt Point) {
+	w.buffer.WriteString(fmt.Sprintf("%d,%d",
+		int(math.Round(pt.X*w.units)), int(math.Round(pt.Y*w.units))))
+}
+
+func (w *HPGLWriter) Write(out io.Writer) {
+	w.buffer.WriteString("PU;\n")
+	w.buffer.WriteString("SP0;\n")
+	out.Write(w.buffer.Bytes())
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHPGLWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []HPGLWriterOption
+		draw     func(w *HPGLWriter)
+		expected string
+	}{
+		{
+			name: "square",
+			opts: []HPGLWriterOption{WithPlotterUnits(10)},
+			draw: func(w *HPGLWriter) {
+				w.AddPolygon(NewPolygon(NewRectangle(0, 0, 1, 2)))
+			},
+			expected: "IN;\nSP1;\nPU0,0;\nPD0,10,20,10,20,0,0,0;\nPU;\nSP0;\n",
+		},
+		{
+			name: "open ring is closed",
+			opts: []HPGLWriterOption{WithPlotterUnits(1)},
+			draw: func(w *HPGLWriter) {
+				w.AddRing(Ring{{0, 0}, {0, 1}, {1, 1}}, layerPart)
+			},
+			expected: "IN;\nSP1;\nPU0,0;\nPD0,1,1,1,0,0;\nPU;\nSP0;\n",
+		},
+		{
+			name: "pen mapping",
+			opts: []HPGLWriterOption{WithPlotterUnits(1), WithPen(layerHole, 2), WithPen(layerSheet, 3)},
+			draw: func(w *HPGLWriter) {
+				w.AddSheet(4, 4)
+				w.AddPolygon(NewPolygon(NewRectangle(0, 0, 4, 4), NewRectangle(1, 1, 2, 2)))
+			},
+			expected: "IN;\n" +
+				"SP3;\nPU0,0;\nPD0,4,4,4,4,0,0,0;\n" +
+				"SP2;\nPU1,1;\nPD1,3,3,3,3,1,1,1;\n" +
+				"SP1;\nPU0,0;\nPD0,4,4,4,4,0,0,0;\n" +
+				"PU;\nSP0;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewHPGLWriter(tt.opts...)
+			tt.draw(w)
+
+			var buf bytes.Buffer
+			w.Write(&buf)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestHPGLWriter_AddCutSequence(t *testing.T) {
+	disc := NewCircleContour(1, 1, 1)
+	placed := []Polygon{(Shape{Outer: disc}).Flatten(0.1)}
+	curves := []*Shape{{Outer: disc}}
+
+	tests := []struct {
+		name string
+		opts LeadOptions
+		arcs int
+	}{
+		{name: "true arcs without leads", arcs: 1},
+		{name: "flattened ring with leads", opts: LeadOptions{Type: leadLine, Length: 0.5}},
+		{name: "flattened ring with tabs", opts: LeadOptions{Tabs: 2, TabWidth: 0.1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewHPGLWriter(WithPlotterUnits(1))
+			w.AddCutSequence(NewCutSequence(placed, NewPoint(0, 0)), tt.opts, curves)
+
+			var buf bytes.Buffer
+			w.Write(&buf)
+			assert.Equal(t, tt.arcs, strings.Count(buf.String(), "AA"))
+		})
+	}
+}
+
+func TestPenFlag_Set(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected penFlag
+		err      string
+	}{
+		{
+			name:     "hole pen",
+			value:    "hole=2",
+			expected: penFlag{layerHole: 2},
+		},
+		{
+			name:  "unknown layer",
+			value: "holes=2",
+			err:   `unknown layer "holes"`,
+		},
+		{
+			name:  "zero pen",
+			value: "part=0",
+			err:   "must be positive",
+		},
+		{
+			name:  "missing pen",
+			value: "part",
+			err:   "expected layer=pen",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pens := penFlag{}
+			err := pens.Set(tt.value)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, pens)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Job describes how the parts of the dataset are nested
+type Job struct {
+	// the rule ranking the positions of the parts, the flag is used if it is empty
+	PlacementRule string `json:"placement_rule,omitempty"`
+	// the parts placed at the given positions before the other parts
+	Locked []LockedPart `json:"locked,omitempty"`
+	// the priorities of the pieces and the filler pieces
+	Pieces []PieceOptions `json:"pieces,omitempty"`
+	// the sheets of the materials, the parts without the material are nested onto the sheet of the dataset
+	Sheets []SheetOptions `json:"sheets,omitempty"`
+}
+
+// SheetOptions is the sheet the parts of the material are nested onto
+type SheetOptions struct {
+	Material string `json:"material"`
+	// the size of the sheet in the units of the dataset
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+// PieceOptions describes how the parts of the piece of the dataset are placed
+type PieceOptions struct {
+	// the id of the piece in the dataset
+	ID string `json:"id"`
+	// the parts of the higher priority are placed first
+	Priority int `json:"priority"`
+	// true if the parts of the piece beyond the min are placed
+	// only into the space left by the other parts
+	Filler bool `json:"filler"`
+	// the number of the parts of the filler piece which must be placed
+	Min int `json:"min"`
+	// the parts of the different materials are nested separately onto the sheets of their materials
+	Material string `json:"material,omitempty"`
+	// the repeat of the fabric pattern the parts are aligned to in the output units
+	Repeat *Repeat `json:"repeat,omitempty"`
+}
+
+// LockedPart is the part placed at the position and the angle given by the job.
+// The position is the offset of the rotated part moved to the origin in the output units.
+type LockedPart struct {
+	// the number of the part in the dataset
+	Part  int     `json:"part"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+}
+
+// LoadJob reads the job from the file and checks it against the parts
+// made of the pieces with the ids
+func LoadJob(path string, ids []string) (*Job, error) {
+	numParts := len(ids)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job: %w", err)
+	}
+
+	if job.PlacementRule != "" {
+		if _, ok := placementRules[job.PlacementRule]; !ok {
+			return nil, fmt.Errorf("unknown placement rule %q", job.PlacementRule)
+		}
+	}
+
+	locked := make(map[int]bool, len(job.Locked))
+	for _, l := range job.Locked {
+		if l.Part < 0 || l.Part >= numParts {
+			return nil, fmt.Errorf("locked part %d does not exist, the dataset has %d parts", l.Part, numParts)
+		}
+		if locked[l.Part] {
+			return nil, fmt.Errorf("part %d is locked more than once", l.Part)
+		}
+		locked[l.Part] = true
+	}
+
+	materials := make(map[string]bool, len(job.Sheets))
+	for _, sheet := range job.Sheets {
+		if sheet.Material == "" {
+			return nil, fmt.Errorf("sheet of %.4f x %.4f has no material", sheet.Width, sheet.Height)
+		}
+		if materials[sheet.Material] {
+			return nil, fmt.Errorf("sheet of material %q is listed more than once", sheet.Material)
+		}
+		if sheet.Width <= 0 || sheet.Height <= 0 {
+			return nil, fmt.Errorf("sheet of material %q must have a positive size, got %.4f x %.4f", sheet.Material, sheet.Width, sheet.Height)
+		}
+		materials[sheet.Material] = true
+	}
+
+	counts := make(map[string]int)
+	for _, id := range ids {
+		counts[id]++
+	}
+	pieces := make(map[string]bool, len(job.Pieces))
+	for _, piece := range job.Pieces {
+		count, exists := counts[piece.ID]
+		if !exists {
+			return nil, fmt.Errorf("piece %q does not exist", piece.ID)
+		}
+		if pieces[piece.ID] {
+			return nil, fmt.Errorf("piece %q is listed more than once", piece.ID)
+		}
+		pieces[piece.ID] = true
+		if !piece.Filler && piece.Min != 0 {
+			return nil, fmt.Errorf("piece %q is not a filler, but has the min number of parts", piece.ID)
+		}
+		if piece.Min < 0 || piece.Min > count {
+			return nil, fmt.Errorf("min number of parts of piece %q must be between 0 and %d, got %d", piece.ID, count, piece.Min)
+		}
+		if piece.Material != "" && !materials[piece.Material] {
+			return nil, fmt.Errorf("piece %q has material %q without a sheet", piece.ID, piece.Material)
+		}
+		if r := piece.Repeat; r != nil && (r.X < 0 || r.Y < 0 || r.Tolerance < 0) {
+			return nil, fmt.Errorf("repeat of piece %q must have non-negative intervals and tolerance", piece.ID)
+		}
+	}
+
+	return &job, nil
+}
+
+// CheckRepeats returns an error if the strips of any of the widths miss the repeats of a piece,
+// so the parts of the piece could never be placed
+func (j *Job) CheckRepeats(steps []float64, horizontal bool) error {
+	for _, piece := range j.Pieces {
+		if piece.Repeat == nil {
+			continue
+		}
+		for _, step := range steps {
+			if err := piece.Repeat.checkStrips(step, horizontal); err != nil {
+				return fmt.Errorf("piece %q: %w", piece.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply sets the priorities, the materials and the repeats of the parts and marks the parts of the filler pieces
+// beyond the min number as the fillers
+func (j *Job) Apply(parts []*Part) {
+	options := make(map[string]PieceOptions, len(j.Pieces))
+	for _, piece := range j.Pieces {
+		options[piece.ID] = piece
+	}
+
+	mandatory := make(map[string]int)
+	for _, part := range parts {
+		piece := options[part.PieceID]
+		part.Priority = piece.Priority
+		part.Material = piece.Material
+		part.Repeat = piece.Repeat
+		part.Filler = piece.Filler && mandatory[part.PieceID] >= piece.Min
+		if !part.Filler {
+			mandatory[part.PieceID]++
+		}
+	}
+}
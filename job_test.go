@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected *Job
+		err      string
+	}{
+		{
+			name: "locked parts",
+			data: `{"placement_rule": "max-contact", "locked": [{"part": 2, "x": 10, "y": 5, "angle": 90}]}`,
+			expected: &Job{
+				PlacementRule: ruleMaxContact,
+				Locked:        []LockedPart{{Part: 2, X: 10, Y: 5, Angle: 90}},
+			},
+		},
+		{
+			name: "unknown part",
+			data: `{"locked": [{"part": 3}]}`,
+			err:  "locked part 3 does not exist",
+		},
+		{
+			name: "part locked twice",
+			data: `{"locked": [{"part": 1}, {"part": 1, "x": 5}]}`,
+			err:  "part 1 is locked more than once",
+		},
+		{
+			name: "pieces",
+			data: `{"pieces": [{"id": "a", "priority": 1, "filler": true, "min": 1}, {"id": "b", "priority": 2}]}`,
+			expected: &Job{
+				Pieces: []PieceOptions{{ID: "a", Priority: 1, Filler: true, Min: 1}, {ID: "b", Priority: 2}},
+			},
+		},
+		{
+			name: "unknown piece",
+			data: `{"pieces": [{"id": "c"}]}`,
+			err:  `piece "c" does not exist`,
+		},
+		{
+			name: "min of the piece which is not a filler",
+			data: `{"pieces": [{"id": "a", "min": 1}]}`,
+			err:  `piece "a" is not a filler`,
+		},
+		{
+			name: "min above the number of the parts",
+			data: `{"pieces": [{"id": "b", "filler": true, "min": 2}]}`,
+			err:  "must be between 0 and 1, got 2",
+		},
+		{
+			name: "materials",
+			data: `{"pieces": [{"id": "a", "material": "steel"}], "sheets": [{"material": "steel", "width": 50, "height": 20}]}`,
+			expected: &Job{
+				Pieces: []PieceOptions{{ID: "a", Material: "steel"}},
+				Sheets: []SheetOptions{{Material: "steel", Width: 50, Height: 20}},
+			},
+		},
+		{
+			name: "material without a sheet",
+			data: `{"pieces": [{"id": "a", "material": "steel"}]}`,
+			err:  `piece "a" has material "steel" without a sheet`,
+		},
+		{
+			name: "sheet without a material",
+			data: `{"sheets": [{"width": 50, "height": 20}]}`,
+			err:  "has no material",
+		},
+		{
+			name: "sheet listed twice",
+			data: `{"sheets": [{"material": "steel", "width": 50, "height": 20}, {"material": "steel", "width": 10, "height": 10}]}`,
+			err:  `sheet of material "steel" is listed more than once`,
+		},
+		{
+			name: "empty sheet",
+			data: `{"sheets": [{"material": "steel", "width": 50}]}`,
+			err:  "must have a positive size",
+		},
+		{
+			name: "repeat",
+			data: `{"pieces": [{"id": "a", "repeat": {"x": 5, "offset_x": 1, "tolerance": 0.5}}]}`,
+			expected: &Job{
+				Pieces: []PieceOptions{{ID: "a", Repeat: &Repeat{X: 5, OffsetX: 1, Tolerance: 0.5}}},
+			},
+		},
+		{
+			name: "negative repeat",
+			data: `{"pieces": [{"id": "a", "repeat": {"y": -5}}]}`,
+			err:  `repeat of piece "a" must have non-negative intervals and tolerance`,
+		},
+		{
+			name: "unknown placement rule",
+			data: `{"placement_rule": "top-right"}`,
+			err:  `unknown placement rule "top-right"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "job.json")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.data), 0o644))
+
+			job, err := LoadJob(path, []string{"a", "a", "b"})
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, job)
+		})
+	}
+}
+
+func TestJob_Apply(t *testing.T) {
+	job := &Job{Pieces: []PieceOptions{{ID: "a", Priority: 1, Filler: true, Min: 1}, {ID: "b", Priority: 2, Material: "steel"}}}
+	parts := []*Part{{PieceID: "a"}, {PieceID: "b"}, {PieceID: "a"}, {PieceID: "c"}, {PieceID: "a"}}
+
+	job.Apply(parts)
+
+	var got []Part
+	for _, part := range parts {
+		got = append(got, *part)
+	}
+	assert.Equal(t, []Part{
+		{PieceID: "a", Priority: 1},
+		{PieceID: "b", Priority: 2, Material: "steel"},
+		{PieceID: "a", Priority: 1, Filler: true},
+		{PieceID: "c"},
+		{PieceID: "a", Priority: 1, Filler: true},
+	}, got)
+}
+
+func TestJob_CheckRepeats(t *testing.T) {
+	tests := []struct {
+		name       string
+		repeat     Repeat
+		horizontal bool
+		err        string
+	}{
+		{
+			name:   "tolerance of half of the strip",
+			repeat: Repeat{X: 3.3, Tolerance: 1},
+		},
+		{
+			name:   "interval on the strips",
+			repeat: Repeat{X: 4, OffsetX: 2},
+		},
+		{
+			name:   "interval off the strips",
+			repeat: Repeat{X: 3.3, Tolerance: 0.1},
+			err:    `piece "a": tolerance 0.1 of the repeat must be at least half of the strip width 2`,
+		},
+		{
+			name:   "offset off the coarse strips",
+			repeat: Repeat{X: 4, OffsetX: 1},
+			err:    `piece "a": tolerance 0 of the repeat must be at least half of the strip width 2`,
+		},
+		{
+			name:       "free axis along the horizontal strips",
+			repeat:     Repeat{X: 3.3},
+			horizontal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{Pieces: []PieceOptions{{ID: "a", Repeat: &tt.repeat}}}
+			err := job.CheckRepeats([]float64{2, 1}, tt.horizontal)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+const (
+	leadNone = ""
+	leadLine = "line"
+	leadArc  = "arc"
+)
+
+// the number of segments used to approximate a lead arc
+const leadArcSegments = 8
+
+// LeadOptions configures the lead-ins, lead-outs and micro-joints of the contours
+type LeadOptions struct {
+	// the type of the lead: none, line or arc
+	Type string
+	// the length of the line lead or the radius of the arc lead
+	Length float64
+	// the number of micro-joints (uncut tabs) per contour
+	Tabs int
+	// the width of a micro-joint
+	TabWidth float64
+}
+
+// CutPath represents the tool path of a single contour
+type CutPath struct {
+	LeadIn []Point
+	// the sections of the contour between micro-joints
+	Cuts    [][]Point
+	LeadOut []Point
+	// the centers of the micro-joints
+	Tabs []Point
+}
+
+// NewCutPath builds the tool path of the contour. The leads are placed in the scrap:
+// outside the contour of a part and inside the contour of a hole.
+func NewCutPath(contour CutContour, opts LeadOptions) CutPath {
+	ring := contour.Ring
+	if !ring.IsClosed() {
+		ring = append(ring[:len(ring):len(ring)], ring[0])
+	}
+
+	if len(ring) < 3 {
+		return CutPath{Cuts: [][]Point{ring}}
+	}
+
+	// the cut starts right after a shared line, so the leads never run along it
+	if at := pierceDistance(ring, contour.Shared); at > 0 {
+		ring = rotateRing(ring, at)
+	}
+	path := CutPath{Cuts: [][]Point{ring}}
+
+	perimeter := ring.Length()
+
+	// the sections of the contour which are not cut
+	var gaps []Range
+	if opts.Tabs > 0 && float64(opts.Tabs)*opts.TabWidth < perimeter {
+		step := perimeter / float64(opts.Tabs)
+		for i := 0; i < opts.Tabs; i++ {
+			center := step * (float64(i) + 0.5)
+			gaps = append(gaps, NewRange(center-opts.TabWidth/2, center+opts.TabWidth/2))
+			path.Tabs = append(path.Tabs, ringSection(ring, center, center)[0])
+		}
+	}
+	gaps = append(gaps, sharedSections(ring, contour.Shared)...)
+
+	if len(gaps) > 0 {
+		path.Cuts = nil
+		from := 0.0
+		for _, gap := range mergeRanges(gaps) {
+			if gap.Start > from {
+				path.Cuts = append(path.Cuts, ringSection(ring, from, gap.Start))
+			}
+			from = max(from, gap.End)
+		}
+		if from < perimeter {
+			path.Cuts = append(path.Cuts, ringSection(ring, from, perimeter))
+		}
+	}
+	if len(path.Cuts) == 0 {
+		// the whole contour is cut by the other parts
+		return path
+	}
+
+	// the clockwise ring has the inside on the right side of its edges
+	scrapLeft := (ring.Area() > 0) != contour.Hole
+
+	// the lead-in joins the start of the first cut and the lead-out leaves the end of the last one,
+	// they meet at the start of the ring unless the ring ends with a shared line
+	firstCut, lastCut := path.Cuts[0], path.Cuts[len(path.Cuts)-1]
+	start, end := firstCut[0], lastCut[len(lastCut)-1]
+	first, _ := endDirections(firstCut)
+	_, last := endDirections(lastCut)
+
+	switch opts.Type {
+	case leadLine:
+		path.LeadIn = []Point{
+			add(start, scale(normal(first, scrapLeft), opts.Length)),
+			start,
+		}
+		path.LeadOut = []Point{
+			end,
+			add(end, scale(normal(last, scrapLeft), opts.Length)),
+		}
+	case leadArc:
+		path.LeadIn = leadInArc(start, first, normal(first, scrapLeft), opts.Length)
+		path.LeadOut = leadOutArc(end, last, normal(last, scrapLeft), opts.Length)
+	}
+
+	return path
+}
+
+// Strokes returns the continuous pen down moves of the path
+func (p CutPath) Strokes() [][]Point {
+	if len(p.Cuts) == 0 {
+		// the whole contour is cut by the other parts
+		return nil
+	}
+
+	strokes := make([][]Point, len(p.Cuts))
+	for i, cut := range p.Cuts {
+		strokes[i] = append([]Point(nil), cut...)
+	}
+
+	if len(p.LeadIn) > 0 {
+		strokes[0] = append(p.LeadIn[:len(p.LeadIn)-1:len(p.LeadIn)-1], strokes[0]...)
+	}
+	if len(p.LeadOut) > 0 {
+		last := len(strokes) - 1
+		strokes[last] = append(strokes[last], p.LeadOut[1:]...)
+	}
+
+	return strokes
+}
+
+// leadInArc returns a quarter arc which ends at the start point tangent to the first edge
+func leadInArc(start, dir, norm Point, radius float64) []Point {
+	center := add(start, scale(norm, radius))
+	points := make([]Point, 0, leadArcSegments+1)
+	for i := 0; i <= leadArcSegments; i++ {
+		t := math.Pi / 2 * float64(i) / leadArcSegments
+		points = append(points, add(center, add(scale(dir, -radius*math.Cos(t)), scale(norm, -radius*math.Sin(t)))))
+	}
+	points[len(points)-1] = start
+	return points
+}
+
+// leadOutArc returns a quarter arc which starts at the end point tangent to the last edge
+func leadOutArc(end, dir, norm Point, radius float64) []Point {
+	center := add(end, scale(norm, radius))
+	points := make([]Point, 0, leadArcSegments+1)
+	for i := 0; i <= leadArcSegments; i++ {
+		t := math.Pi / 2 * float64(i) / leadArcSegments
+		points = append(points, add(center, add(scale(norm, -radius*math.Cos(t)), scale(dir, radius*math.Sin(t)))))
+	}
+	points[0] = end
+	return points
+}
+
+// sharedSections returns the sections of the ring lying on the shared lines
+// as distances along the ring
+func sharedSections(ring Ring, shared []Line) []Range {
+	var sections []Range
+	var traveled float64
+	for i := 0; i < len(ring)-1; i++ {
+		edge := Line{Start: ring[i], End: ring[i+1]}
+		for _, l := range shared {
+			if from, to, ok := collinearOverlap(edge, l, commonLineTolerance); ok {
+				sections = append(sections, NewRange(traveled+from, traveled+to))
+			}
+		}
+		traveled += edge.Length()
+	}
+	return sections
+}
+
+// pierceDistance returns the distance along the closed ring the cut starts at.
+// It is the end of the first shared section, so the contour between the shared lines
+// is cut in one stroke, or 0 if no line is shared or the whole ring is shared.
+func pierceDistance(ring Ring, shared []Line) float64 {
+	sections := mergeRanges(sharedSections(ring, shared))
+	if len(sections) == 0 || sections[0].End >= ring.Length()-epsilon {
+		return 0
+	}
+	return sections[0].End
+}
+
+// rotateRing returns the closed ring starting at the distance along the given one
+func rotateRing(ring Ring, at float64) Ring {
+	rotated := Ring(ringSection(ring, at, ring.Length()))
+	return append(rotated, ringSection(ring, 0, at)[1:]...)
+}
+
+// mergeRanges returns the sorted union of the ranges
+func mergeRanges(ranges []Range) []Range {
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b Range) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	var merged []Range
+	for _, r := range sorted {
+		if last := len(merged) - 1; last >= 0 && r.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// ringSection returns the part of the ring between the given distances along it
+func ringSection(ring Ring, from, to float64) []Point {
+	var (
+		section  []Point
+		traveled float64
+	)
+	for i := 0; i < len(ring)-1; i++ {
+		length := ring[i].Distance(ring[i+1])
+		if length == 0 {
+			continue
+		}
+		edgeStart, edgeEnd := traveled, traveled+length
+		traveled = edgeEnd
+
+		if edgeEnd <= from {
+			continue
+		}
+		if len(section) == 0 {
+			section = append(section, interpolate(ring[i], ring[i+1], (from-edgeStart)/length))
+		}
+		if edgeEnd >= to {
+			return append(section, interpolate(ring[i], ring[i+1], (to-edgeStart)/length))
+		}
+		section = append(section, ring[i+1])
+	}
+	if len(section) == 0 && len(ring) > 0 {
+		return []Point{ring[len(ring)-1]}
+	}
+	return section
+}
+
+// endDirections returns the directions of the first and the last edges of the polyline
+// skipping the repeated points
+func endDirections(ring Ring) (Point, Point) {
+	var first, last Point
+	for i := 1; i < len(ring); i++ {
+		if ring[i] != ring[0] {
+			first = direction(ring[0], ring[i])
+			break
+		}
+	}
+	for i := len(ring) - 2; i >= 0; i-- {
+		if ring[i] != ring[len(ring)-1] {
+			last = direction(ring[i], ring[len(ring)-1])
+			break
+		}
+	}
+	return first, last
+}
+
+// direction returns the unit vector from a to b
+func direction(a, b Point) Point {
+	length := a.Distance(b)
+	if length == 0 {
+		return Point{}
+	}
+	return NewPoint((b.X-a.X)/length, (b.Y-a.Y)/length)
+}
+
+// normal returns the left or the right unit normal of the direction
+func normal(dir Point, left bool) Point {
+	if left {
+		return NewPoint(-dir.Y, dir.X)
+	}
+	return NewPoint(dir.Y, -dir.X)
+}
+
+func interpolate(a, b Point, t float64) Point {
+	return NewPoint(a.X+(b.X-a.X)*t, a.Y+(b.Y-a.Y)*t)
+}
+
+func add(a, b Point) Point {
+	return NewPoint(a.X+b.X, a.Y+b.Y)
+}
+
+func scale(p Point, factor float64) Point {
+	return NewPoint(p.X*factor, p.Y*factor)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCutPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		contour  CutContour
+		opts     LeadOptions
+		expected CutPath
+	}{
+		{
+			name:    "no leads",
+			contour: CutContour{Ring: NewRectangle(0, 0, 2, 2)},
+			expected: CutPath{
+				Cuts: [][]Point{NewRectangle(0, 0, 2, 2)},
+			},
+		},
+		{
+			name:    "line lead outside the part",
+			contour: CutContour{Ring: NewRectangle(0, 0, 2, 2)},
+			opts:    LeadOptions{Type: leadLine, Length: 1},
+			expected: CutPath{
+				LeadIn:  []Point{{-1, 0}, {0, 0}},
+				Cuts:    [][]Point{NewRectangle(0, 0, 2, 2)},
+				LeadOut: []Point{{0, 0}, {0, -1}},
+			},
+		},
+		{
+			name:    "line lead inside the hole",
+			contour: CutContour{Ring: NewRectangle(0, 0, 2, 2), Hole: true},
+			opts:    LeadOptions{Type: leadLine, Length: 1},
+			expected: CutPath{
+				LeadIn:  []Point{{1, 0}, {0, 0}},
+				Cuts:    [][]Point{NewRectangle(0, 0, 2, 2)},
+				LeadOut: []Point{{0, 0}, {0, 1}},
+			},
+		},
+		{
+			name: "leads off the shared lines through the start",
+			contour: CutContour{
+				Ring:   NewRectangle(0, 0, 2, 2),
+				Shared: []Line{{Start: NewPoint(2, 0), End: NewPoint(0, 0)}, {Start: NewPoint(0, 0), End: NewPoint(0, 1)}},
+			},
+			opts: LeadOptions{Type: leadLine, Length: 1},
+			expected: CutPath{
+				LeadIn:  []Point{{-1, 1}, {0, 1}},
+				Cuts:    [][]Point{{{0, 1}, {0, 2}, {2, 2}, {2, 0}}},
+				LeadOut: []Point{{2, 0}, {3, 0}},
+			},
+		},
+		{
+			name:    "micro-joints",
+			contour: CutContour{Ring: NewRectangle(0, 0, 2, 2)},
+			opts:    LeadOptions{Tabs: 2, TabWidth: 1},
+			expected: CutPath{
+				Cuts: [][]Point{
+					{{0, 0}, {0, 1.5}},
+					{{0.5, 2}, {2, 2}, {2, 0.5}},
+					{{1.5, 0}, {0, 0}},
+				},
+				Tabs: []Point{{0, 2}, {2, 0}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCutPath(tt.contour, tt.opts)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestCutPath_ArcLead(t *testing.T) {
+	path := NewCutPath(CutContour{Ring: NewRectangle(0, 0, 2, 2)}, LeadOptions{Type: leadArc, Length: 1})
+
+	// the arc starts one radius back along the first edge and one radius outside
+	assert.InDelta(t, -1, path.LeadIn[0].X, epsilon)
+	assert.InDelta(t, -1, path.LeadIn[0].Y, epsilon)
+	assert.Equal(t, NewPoint(0, 0), path.LeadIn[len(path.LeadIn)-1])
+	for _, pt := range path.LeadIn[:len(path.LeadIn)-1] {
+		assert.Less(t, pt.X, 0.0)
+	}
+
+	strokes := path.Strokes()
+	assert.Len(t, strokes, 1)
+	assert.Equal(t, path.LeadIn[0], strokes[0][0])
+	assert.Equal(t, path.LeadOut[len(path.LeadOut)-1], strokes[0][len(strokes[0])-1])
+}
@@ -2,13 +2,21 @@ package main
 
 import (
 	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"math/rand"
 	"os"
+	"runtime"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -16,8 +24,6 @@ const (
 	defaultResolution  float64 = 0.1
 )
 
-var defaultAllowedRotations = []int{0}
-
 type intListFlag []int
 
 func (i *intListFlag) String() string {
@@ -33,26 +39,142 @@ func (i *intListFlag) Set(value string) error {
 	return nil
 }
 
-var (
-	sheetHeight float32 = 200
-	maxLength           = int(200 / defaultResolution)
+type floatListFlag []float64
+
+func (f *floatListFlag) String() string {
+	return fmt.Sprintf("%v", *f)
+}
+
+func (f *floatListFlag) Set(value string) error {
+	valueFloat, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, valueFloat)
+	return nil
+}
+
+// penFlag maps a layer to a plotter pen, e.g. --pen hole=2
+type penFlag map[string]int
+
+func (p penFlag) String() string {
+	return fmt.Sprintf("%v", map[string]int(p))
+}
+
+func (p penFlag) Set(value string) error {
+	layer, pen, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected layer=pen, got %q", value)
+	}
+	if layer != layerSheet && layer != layerPart && layer != layerHole {
+		return fmt.Errorf("unknown layer %q, expected sheet, part or hole", layer)
+	}
+	penInt, err := strconv.Atoi(pen)
+	if err != nil {
+		return err
+	}
+	if penInt <= 0 {
+		return fmt.Errorf("pen of layer %q must be positive, got %d", layer, penInt)
+	}
+	p[layer] = penInt
+	return nil
+}
+
+const (
+	outputFormatSVG  = "svg"
+	outputFormatHPGL = "hpgl"
+)
+
+// the sheets of the materials, the parts without the material are nested onto the sheet of the dataset
+var sheets = map[string]Sheet{}
+
+const commandValidate = "validate"
+
+const (
+	placementBLF = "blf"
+	placementNFP = "nfp"
 )
 
 var (
-	dataset          *string
-	scaleOutput      *float64
-	resolution       *float64
-	allowedRotations intListFlag = defaultAllowedRotations
+	dataset          = flag.String("dataset", "datasets/shirts_2007-05-15/shirts.xml", "dataset file")
+	scaleOutput      = flag.Float64("scale-output", defaultScaleOutput, "scale factor")
+	resolution       = flag.Float64("resolution", defaultResolution, "resolution")
+	resolutions      floatListFlag
+	allowedRotations intListFlag
+	rotationMin      = flag.Int("rotation-min", angularMin, "min rotation in degrees")
+	rotationMax      = flag.Int("rotation-max", angularMax, "max rotation in degrees, inclusive")
+	rotationStep     = flag.Int("rotation-step", angularInterval, "step between the rotations in degrees")
+	rotationRefine   = flag.Int("rotation-refine", 0, "number of the levels of the intermediate angles tried around the placed parts")
+	outputFormat     = flag.String("output-format", outputFormatSVG, "output format (svg, hpgl)")
+	plotterUnits     = flag.Float64("plotter-units", defaultPlotterUnits, "plotter units per unit of the dataset, e.g. 40 for the dataset in millimetres")
+	pens             = penFlag{}
+	leads            LeadOptions
+	commonLines      = flag.Bool("common-lines", false, "cut the edges shared by adjacent parts once")
+	compact          = flag.Bool("compact", false, "move the placed parts left and down after the placement")
+	compactRotate    = flag.Bool("compact-rotate", false, "try the other orientations of the parts during the compaction")
+	remnantsFile     = flag.String("remnants", "", "remnant inventory file, the remnants of the sheet are added to it")
+	useRemnants      = flag.Bool("use-remnants", false, "nest onto the remnants of the inventory before the new sheet")
+	remnantMinSize   = flag.Float64("remnant-min-size", 10, "min width and height of a remnant in the output units")
+	job              = &Job{}
+	partial          = flag.Bool("partial", false, "skip the parts which do not fit into the sheet instead of failing")
+	simplifyTol      = flag.Float64("simplify", 0, "max deviation of the simplified contours, 0 disables simplification")
+	simplifyMethod   = flag.String("simplify-method", simplifyDouglasPeucker, "simplification method (dp, vw)")
+	placement        = flag.String("placement", placementBLF, "placement engine (blf, nfp)")
+	placementRule    = flag.String("placement-rule", ruleBottomLeft, "rule ranking the positions of the part orientations for blf (bottom-left, gravity-center, max-contact, min-envelope)")
+	strips           = flag.String("strips", stripsVertical, "strip direction, the sheet grows along x for vertical strips and along y for horizontal ones")
+	nfpCache         = NewNFPCache()
+	orientationCache = NewOrientationCache("")
+	workers          = flag.Int("workers", runtime.NumCPU(), "number of the workers generating the orientations")
 )
 
 func main() {
-
-	dataset = flag.String("dataset", "datasets/shirts_2007-05-15/shirts.xml", "dataset file")
-	scaleOutput = flag.Float64("scale-output", defaultScaleOutput, "scale factor")
-	resolution = flag.Float64("resolution", defaultResolution, "resolution")
-	flag.Var(&allowedRotations, "rotations", "allowed rotations in degrees")
+	flag.Var(&resolutions, "resolutions", "resolutions from coarse to fine for the multi-resolution placement compacted at the finest one, overrides --resolution")
+	flag.Var(&allowedRotations, "rotations", "allowed rotations in degrees, overrides the rotation range")
+	flag.Var(pens, "pen", "plotter pen for a layer (sheet, part, hole), e.g. hole=2")
+	flag.StringVar(&leads.Type, "lead", leadNone, "lead-in and lead-out type (line, arc)")
+	flag.Float64Var(&leads.Length, "lead-length", 1, "length of the line lead or radius of the arc lead")
+	flag.IntVar(&leads.Tabs, "tabs", 0, "number of micro-joints per contour")
+	flag.Float64Var(&leads.TabWidth, "tab-width", 0.5, "width of a micro-joint")
+	cacheDir := flag.String("cache-dir", "", "directory of the cached orientations, empty disables the disk cache")
+	jobFile := flag.String("job", "", "job file with the locked parts and the placement rule")
 	flag.Parse()
 
+	if *outputFormat != outputFormatSVG && *outputFormat != outputFormatHPGL {
+		log.Fatalf("unknown output format %q", *outputFormat)
+	}
+	if leads.Type != leadNone && leads.Type != leadLine && leads.Type != leadArc {
+		log.Fatalf("unknown lead type %q", leads.Type)
+	}
+	if *simplifyMethod != simplifyDouglasPeucker && *simplifyMethod != simplifyVisvalingam {
+		log.Fatalf("unknown simplification method %q", *simplifyMethod)
+	}
+	if *placement != placementBLF && *placement != placementNFP {
+		log.Fatalf("unknown placement engine %q", *placement)
+	}
+	if _, ok := placementRules[*placementRule]; !ok {
+		log.Fatalf("unknown placement rule %q", *placementRule)
+	}
+	if *strips != stripsVertical && *strips != stripsHorizontal {
+		log.Fatalf("unknown strip direction %q", *strips)
+	}
+	if *rotationStep <= 0 || *rotationMin > *rotationMax {
+		log.Fatalf("invalid rotation range from %d to %d with step %d", *rotationMin, *rotationMax, *rotationStep)
+	}
+	if *rotationRefine > 0 && len(allowedRotations) > 0 {
+		log.Fatalf("the rotation refinement needs the rotation range instead of the allowed rotations")
+	}
+	if *useRemnants && *remnantsFile == "" {
+		log.Fatalf("the remnants cannot be used without the remnant inventory")
+	}
+	if *workers < 1 {
+		log.Fatalf("the number of workers must be positive, got %d", *workers)
+	}
+	if flag.NArg() > 0 && flag.Arg(0) != commandValidate {
+		log.Fatalf("unknown command %q", flag.Arg(0))
+	}
+
+	orientationCache = NewOrientationCache(*cacheDir)
+
 	println("Loading dataset...")
 
 	f, err := os.Open(*dataset)
@@ -66,32 +188,108 @@ func main() {
 		panic(err)
 	}
 
-	polygons := nesting.GetParts()
+	polygons, shapes, ids, err := prepareParts(&nesting)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	for i, poly := range polygons {
-		minx, miny, _, _ := poly.Bounds()
-		polygons[i] = poly.Offset(NewPoint(-minx, -miny)).Scale(*scaleOutput)
+	if *jobFile != "" {
+		if job, err = LoadJob(*jobFile, ids); err != nil {
+			log.Fatal(err)
+		}
+		if job.PlacementRule != "" {
+			*placementRule = job.PlacementRule
+		}
+	}
+	if *placement == placementNFP && *placementRule != ruleBottomLeft {
+		log.Fatalf("placement rule %q is not supported by the nfp placement", *placementRule)
+	}
+	if *placement == placementNFP && slices.ContainsFunc(job.Pieces, func(piece PieceOptions) bool {
+		return piece.Repeat != nil
+	}) {
+		log.Fatalf("the repeat of the fabric pattern is not supported by the nfp placement")
 	}
 
-	var sheetWidth float32
-	sheetWidth, sheetHeight = nesting.GetBoardSizes()
-	var maxLength = int(float64(sheetWidth) * *scaleOutput / *resolution)
-	sheetHeight *= float32(*scaleOutput)
+	width, height, err := nesting.GetBoardSizes()
+	if err != nil {
+		log.Fatal(err)
+	}
 	*resolution *= *scaleOutput
 
+	// the coarse resolutions go first and the finest one is used for the output
+	sort.Sort(sort.Reverse(sort.Float64Slice(resolutions)))
+	for i := range resolutions {
+		resolutions[i] *= *scaleOutput
+	}
+	if len(resolutions) > 0 {
+		*resolution = resolutions[len(resolutions)-1]
+	}
+	steps := []float64{*resolution}
+	if len(resolutions) > 0 {
+		steps = resolutions
+	}
+	if err := job.CheckRepeats(steps, *strips == stripsHorizontal); err != nil {
+		log.Fatal(err)
+	}
+
+	// the sheets are limited to the strips of the finest level, the coarse levels fit into them
+	sheets[""] = newSheet(width, height, *resolution)
+	for _, sheet := range job.Sheets {
+		sheets[sheet.Material] = newSheet(sheet.Width, sheet.Height, *resolution)
+	}
+
 	fmt.Println("Dataset loaded")
 	fmt.Println("Parts:", len(polygons))
-	fmt.Println("Board size:", maxLength, sheetHeight)
+	fmt.Println("Board size:", sheets[""].MaxLength, sheets[""].Height)
 
-	if err := run(polygons); err != nil {
+	if err := run(polygons, shapes, ids); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// prepareParts returns the repaired parts of the dataset moved to the origin and scaled
+// to the output units, their curves and the ids of the pieces they are made of
+func prepareParts(nesting *Nesting) ([]Polygon, []*Shape, []string, error) {
+	polygons, shapes, ids, err := nesting.GetPieces()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for i, poly := range polygons {
+		poly, diagnostics, err := RepairPolygon(poly)
+		if len(diagnostics) > 0 {
+			fmt.Printf("Part %d: %s\n", i, FormatDiagnostics(diagnostics))
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("part %d of piece %q cannot be nested: %w", i, ids[i], err)
+		}
+
+		minx, miny, _, _ := poly.Bounds()
+		polygons[i] = poly.Offset(NewPoint(-minx, -miny)).Scale(*scaleOutput)
+		if shapes[i] != nil {
+			// the curves are moved and scaled along with their polygon
+			factor := *scaleOutput
+			curves := shapes[i].Transform(func(pt Point) Point {
+				pt = pt.Offset(NewPoint(-minx, -miny))
+				return NewPoint(pt.X*factor, pt.Y*factor)
+			})
+			shapes[i] = &curves
+		}
+
+		if *simplifyTol > 0 {
+			polygons[i] = polygons[i].Simplify(*simplifyMethod, *simplifyTol)
+		}
+	}
+	return polygons, shapes, ids, nil
+}
+
 const (
 	angularInterval = 15 // degrees
-	angularMin      = 180
-	angularMax      = 180
+	angularMin      = 0
+	angularMax      = 0
+
+	// the weight of the sheet length the unplaced parts would need in the fitness
+	unplacedPenalty = 2
 
 	populationSize = 20
 	elitismRate    = 0.1
@@ -100,52 +298,315 @@ const (
 )
 
 type Part struct {
-	Orientations       []orientation
-	Shape              Polygon
+	Orientations []orientation
+	Shape        Polygon
+	// the curves the shape is flattened from, nil if the part is a polygon
+	Curves             *Shape
 	Offset             Offset
 	BestOrientationNum int
+	// the id of the piece of the dataset the part is made of
+	PieceID string
+	// the parts of the higher priority are placed first
+	Priority int
+	// true if the part is placed only into the space left by the other parts
+	Filler bool
+	// the parts of the different materials are nested onto the different sheets
+	Material string
+	// the positions allowed by the repeat of the fabric pattern, nil if the part is placed anywhere
+	Repeat *Repeat
 }
 
 func (p Part) bestOrienation() orientation {
 	return p.Orientations[p.BestOrientationNum]
 }
 
-func run(figures []Polygon) error {
+// run nests the figures made of the pieces with the ids, the curves of the figures
+// are drawn instead of the figures where they are not nil.
+// The parts of each material are nested onto the sheet of the material.
+func run(figures []Polygon, curves []*Shape, ids []string) error {
+	started := time.Now()
 	var parts []*Part
 
 	var angles []int
 	if len(allowedRotations) != 0 {
 		angles = allowedRotations
 	} else {
-		angles = rangeSlice(angularMin, angularMax, angularInterval)
+		angles = rangeSlice(*rotationMin, *rotationMax+1, *rotationStep)
 	}
+	angles = distinctAngles(angles)
 
-	for _, fig := range figures {
-		parts = append(parts, &Part{
-			Orientations: createOrientations(fig, angles...),
-			Shape:        fig,
-		})
+	steps := []float64{*resolution}
+	if len(resolutions) > 0 {
+		steps = resolutions
+	}
+
+	// the parts discretized with each resolution
+	levels := make([][]*Part, len(steps))
+	for i, step := range steps {
+		for j, orientations := range createOrientations(figures, step, *workers, angles...) {
+			levels[i] = append(levels[i], &Part{
+				Orientations: orientations,
+				Shape:        figures[j],
+				Curves:       curves[j],
+				PieceID:      ids[j],
+			})
+		}
+		job.Apply(levels[i])
+	}
+	parts = levels[len(levels)-1]
+
+	// the locked parts keep the angle of the job and are always placed
+	for i, step := range steps {
+		for _, l := range job.Locked {
+			levels[i][l.Part].Orientations = []orientation{createOrientation(figures[l.Part], step, l.Angle, true)}
+			levels[i][l.Part].Filler = false
+		}
+	}
+
+	groups := groupParts(parts, job.Locked)
+	for i := range groups {
+		groups[i].sheet = sheets[groups[i].material]
+		if err := lockParts(newPlacer(groups[i].sheet, *resolution), parts, groups[i].locked); err != nil {
+			return err
+		}
+	}
+
+	hits, misses := orientationCache.Stats()
+	fmt.Printf("Orientations: %d computed, %d cached\n", misses, hits)
+
+	// the numbers of the parts of each group nested onto the new sheet besides the locked and the filler ones
+	remaining := make([][]int, len(groups))
+	for i, group := range groups {
+		remaining[i] = group.remaining(parts)
+		drawParts(group, parts, remaining[i], group.fileName("input")+".svg")
+	}
+
+	var inventory *RemnantInventory
+	if *remnantsFile != "" {
+		var err error
+		if inventory, err = LoadRemnantInventory(*remnantsFile); err != nil {
+			return err
+		}
+	}
+	var (
+		// the reports and the validation errors of the layouts on the remnants
+		remnantReports []*Report
+		remnantErrs    []error
+	)
+	if *useRemnants {
+		var used []int
+		for i, group := range groups {
+			// the parts are nested only onto the remnants of their material
+			var nums []int
+			for j, remnant := range inventory.Remnants {
+				if remnant.Material == group.material {
+					nums = append(nums, j)
+				}
+			}
+
+			var layouts []remnantLayout
+			layouts, remaining[i] = nestRemnants(parts, remaining[i], pick(inventory.Remnants, nums), newRemnantFill)
+			for _, layout := range layouts {
+				layout.remnant = nums[layout.remnant]
+				remnant := inventory.Remnants[layout.remnant]
+				name := fmt.Sprintf("remnant-%d", layout.remnant)
+				fmt.Printf("Remnant %d: %d parts\n", layout.remnant, len(layout.parts))
+				if err := writeRemnantLayout(layout, remnant.Outline, name); err != nil {
+					return err
+				}
+				remnantReports = append(remnantReports, remnantReport(layout, remnant))
+				if flag.Arg(0) == commandValidate {
+					remnantErrs = append(remnantErrs, validateRemnant(layout, remnant.Outline))
+				}
+				used = append(used, layout.remnant)
+			}
+		}
+		inventory.Remove(used...)
+
+		nested := len(job.Locked) == 0
+		for _, nums := range remaining {
+			nested = nested && len(nums) == 0
+		}
+		if nested {
+			fmt.Println("All parts are nested onto the remnants")
+			if err := writeReports(remnantReports, started, "output"); err != nil {
+				return err
+			}
+			if err := inventory.Save(*remnantsFile); err != nil {
+				return err
+			}
+			return errors.Join(remnantErrs...)
+		}
+	}
+
+	// the groups do not share the parts and the sheets, so they are optimized concurrently
+	var (
+		best         = make([][]int, len(groups))
+		trajectories = make([][]float32, len(groups))
+		runtimes     = make([]time.Duration, len(groups))
+		wg           sync.WaitGroup
+	)
+	for i, group := range groups {
+		wg.Add(1)
+		go func(i int, group materialGroup) {
+			defer wg.Done()
+			groupStarted := time.Now()
+			best[i], trajectories[i] = optimizeGroup(group, levels, steps, remaining[i])
+			runtimes[i] = time.Since(groupStarted)
+		}(i, group)
 	}
+	wg.Wait()
 
-	drawParts(parts, rangeSlice(0, len(figures), 1), "input.svg")
+	output := "output"
+	reports := make([]*Report, len(groups))
+	for i, group := range groups {
+		report, err := writeGroup(group, parts, best[i])
+		if err != nil {
+			return err
+		}
+		report.RuntimeSeconds = runtimes[i].Seconds()
+		report.Generations = len(trajectories[i])
+		report.FitnessTrajectory = trajectories[i]
+		reports[i] = report
+	}
 
-	fitnessFn := func(i Individual) float32 {
-		order := i.Order()
-		ordered := make([]*Part, len(order))
-		for i, num := range order {
-			ordered[i] = parts[num]
+	// the layouts on the remnants go first as they are nested first
+	if err := writeReports(append(remnantReports, reports...), started, output); err != nil {
+		return err
+	}
+
+	if flag.Arg(0) == commandValidate {
+		errs := remnantErrs
+		for i, group := range groups {
+			errs = append(errs, validateParts(group, parts, best[i]))
 		}
+		return errors.Join(errs...)
+	}
 
-		fill := NewBottomLeftFill(sheetHeight, maxLength)
-		fill.Run(ordered)
+	if inventory != nil {
+		var found int
+		for i, group := range groups {
+			_, fill := placeParts(group, parts, best[i])
+			rects := FindRemnants(sheetVacancy(group.sheet, fill), *resolution, *remnantMinSize)
+			for _, rect := range rects {
+				remnant := rect.remnant(*resolution, *strips == stripsHorizontal)
+				remnant.Material = group.material
+				inventory.Remnants = append(inventory.Remnants, remnant)
+			}
+			found += len(rects)
+		}
+		fmt.Println("Remnants found:", found)
+		return inventory.Save(*remnantsFile)
+	}
 
-		length := calculateSheetLength(parts, *resolution)
-		return -length
+	return nil
+}
+
+// optimizeGroup searches the best order of the remaining parts of the group
+// and returns it with the best fitness after each generation
+func optimizeGroup(group materialGroup, levels [][]*Part, steps []float64, remaining []int) ([]int, []float32) {
+	fitnessAt := func(level int) orderFitness {
+		return func(order []int) float32 {
+			var options []BottomLeftFillOption
+			if level < len(levels)-1 {
+				// the locks are checked at the finest level, the coarse strips round them outward
+				options = append(options, WithOverlappingLocks())
+			}
+			fill := newPlacer(group.sheet, steps[level], options...)
+			if err := lockParts(fill, levels[level], group.locked); err != nil {
+				panic(err)
+			}
+			ordered := pick(levels[level], prioritize(levels[level], order))
+			var unplacedArea float64
+			for _, num := range placeAll(fill, ordered) {
+				unplacedArea += ordered[num].Shape.Area()
+			}
+			// the layout written at the finest level is compacted, so its fitness is too
+			if compactor, ok := fill.(Compactor); ok && level == len(levels)-1 && compacts() {
+				compactor.Compact(*compactRotate)
+			}
+
+			// the unplaced area needs at least its length of the sheet
+			return -float32(fill.Length() + unplacedPenalty*unplacedArea/sheetAcross(group.sheet))
+		}
+	}
+
+	// the locked parts are placed before the order, so only the other parts are permuted
+	best := []int{}
+	var trajectory []float32
+	if len(remaining) > 0 {
+		best, trajectory = optimizeOrder(remaining, levels, fitnessAt)
 	}
 
+	if *rotationRefine > 0 && len(best) > 0 {
+		fitness := refineRotations(levels[len(levels)-1], best, fitnessAt(len(levels)-1),
+			float64(*rotationStep), float64(*rotationMin), float64(*rotationMax), *rotationRefine,
+			func(fig Polygon, angle float64) orientation {
+				return createOrientation(fig, *resolution, angle, true)
+			},
+		)
+		fmt.Printf("Best fitness after rotation refinement: %f\n", fitness)
+	}
+
+	return best, trajectory
+}
+
+// writeGroup writes the layout of the parts of the group placed in the order
+// and returns the report of the placement
+func writeGroup(group materialGroup, parts []*Part, order []int) (*Report, error) {
+	if group.material != "" {
+		fmt.Println("Material:", group.material)
+	}
+
+	var (
+		output = group.fileName("output")
+		nums   []int
+		length float64
+		err    error
+	)
+	if *outputFormat == outputFormatHPGL {
+		nums, length, err = plotParts(group, parts, order, output+".plt")
+	} else {
+		nums, length, err = drawParts(group, parts, order, output+".svg")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := usedSheet(group.sheet, length)
+	report := NewReport(pick(parts, nums), nums, *resolution, width, height)
+	report.Material = group.material
+	report.Fillers = fillerReports(job.Pieces, parts, group.nums, nums)
+	for _, num := range order {
+		if !slices.Contains(nums, num) {
+			report.Leftovers = append(report.Leftovers, num)
+		}
+	}
+	sort.Ints(report.Leftovers)
+	if len(report.Leftovers) > 0 {
+		fmt.Println("Leftover parts:", report.Leftovers)
+	}
+	// the unplaced mandatory parts are the leftovers, the optional fillers are added to them
+	report.Unplaced = len(report.Leftovers)
+	for _, num := range fillers(parts, group.nums) {
+		if !slices.Contains(nums, num) {
+			report.Unplaced++
+		}
+	}
+	for _, filler := range report.Fillers {
+		fmt.Printf("Filler %s: %d of %d placed, min %d\n", filler.PieceID, filler.Placed, filler.Total, filler.Min)
+	}
+	return report, nil
+}
+
+// optimizeOrder searches the best order of the parts with the numbers at the coarsest level
+// and refines it at the finer levels. It returns the order and the best fitness after each generation.
+func optimizeOrder(nums []int, levels [][]*Part, fitnessAt func(level int) orderFitness) ([]int, []float32) {
 	ga := NewGeneticAlgorithm(
-		len(figures),
-		fitnessFn,
+		len(nums),
+		func(i Individual) float32 {
+			return fitnessAt(0)(pick(nums, i.Order()))
+		},
 		WithPopulationSize(populationSize),
 		WithElitismRate(elitismRate),
 		WithMutationRate(mutationRate),
@@ -154,8 +615,159 @@ func run(figures []Polygon) error {
 	ga.Run(numGenerations)
 	fmt.Printf("Best fitness: %f, Order: %v\n", ga.Best().Fitness(), ga.Best().Order())
 
-	drawParts(parts, ga.Best().Order(), "output.svg")
+	best := pick(nums, ga.Best().Order())
+	if len(levels) > 1 {
+		var candidates [][]int
+		for _, individual := range ga.BestN(refineCandidates) {
+			candidates = append(candidates, pick(nums, individual.Order()))
+		}
+
+		fitnesses := make([]orderFitness, len(levels)-1)
+		for i := range fitnesses {
+			fitnesses[i] = fitnessAt(i + 1)
+		}
+
+		var fitness float32
+		best, fitness = refineOrders(candidates, fitnesses)
+		fmt.Printf("Best fitness at resolution %f: %f, Order: %v\n", *resolution, fitness, best)
+
+		best, fitness = localSearch(best, fitnessAt(len(levels)-1), localSearchPasses)
+		fmt.Printf("Best fitness after local search: %f, Order: %v\n", fitness, best)
+	}
+
+	return best, ga.Trajectory()
+}
+
+// pick returns the elements at the positions of the order
+func pick[T any](elems []T, order []int) []T {
+	picked := make([]T, len(order))
+	for i, pos := range order {
+		picked[i] = elems[pos]
+	}
+	return picked
+}
+
+// lockParts places the locked parts at their positions
+func lockParts(fill Placer, parts []*Part, locked []LockedPart) error {
+	for _, l := range locked {
+		if err := fill.Lock(parts[l.Part], NewPoint(l.X, l.Y)); err != nil {
+			return fmt.Errorf("failed to lock part %d: %w", l.Part, err)
+		}
+	}
+	return nil
+}
+
+// newRemnantFill returns the placement engine bounded by the outline of the remnant
+func newRemnantFill(remnant Remnant) *BottomLeftFill {
+	_, _, maxx, maxy := NewPolygon(remnant.Outline).Bounds()
+	options := []BottomLeftFillOption{
+		WithStep(*resolution),
+		WithSheetOutline(remnant.Outline),
+		WithPlacementRule(placementRules[*placementRule]),
+	}
+	height, length := maxy, maxx
+	if *strips == stripsHorizontal {
+		height, length = maxx, maxy
+		options = append(options, WithHorizontalStrips())
+	}
+	return NewBottomLeftFill(float32(height), int(math.Ceil(length / *resolution)), options...)
+}
+
+// sheetVacancy returns the vacant strips of the whole sheet the parts are placed on
+func sheetVacancy(sheet Sheet, fill Placer) OccupancyTable {
+	length, across := float64(sheet.Width), float64(sheet.Height)
+	if *strips == stripsHorizontal {
+		length, across = across, length
+	}
+	columns := int(length / *resolution + epsilon)
+
+	if blf, ok := fill.(*BottomLeftFill); ok {
+		return blf.vacancy(columns)
+	}
+
+	// only the end of the sheet is known to be vacant
+	used := int(math.Ceil(fill.Length() / *resolution - epsilon))
+	table := make(OccupancyTable, columns)
+	for i := range table {
+		table[i] = Strip{}
+		if i >= used {
+			table[i] = Strip{{Start: 0, End: across}}
+		}
+	}
+	return table
+}
+
+// remnantReport returns the report of the parts nested onto the remnant,
+// the utilization is the share of the area of the remnant outline
+func remnantReport(layout remnantLayout, remnant Remnant) *Report {
+	_, _, maxx, maxy := NewPolygon(remnant.Outline).Bounds()
+	report := NewReport(layout.parts, layout.nums, *resolution, maxx, maxy)
+	report.Material = remnant.Material
+	report.Remnant = &layout.remnant
+	report.SheetArea = remnant.Area()
+	if report.SheetArea > 0 {
+		report.Utilization = report.PartsArea / report.SheetArea * 100
+	}
+	return report
+}
+
+// validateRemnant checks that the parts nested onto the remnant lie inside its outline
+// and do not overlap each other
+func validateRemnant(layout remnantLayout, outline Ring) error {
+	violations := ValidateWithin(placedShapes(layout.parts, *resolution), outline)
+	for _, v := range violations {
+		fmt.Println("Violation:", v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("placement on remnant %d is invalid: %d violations", layout.remnant, len(violations))
+	}
+
+	fmt.Printf("Placement on remnant %d is valid\n", layout.remnant)
+	return nil
+}
+
+// writeRemnantLayout writes the parts placed onto the remnant in the output format
+func writeRemnantLayout(layout remnantLayout, outline Ring, name string) error {
+	placed := placedShapes(layout.parts, *resolution)
+	curves := make([]*Shape, len(layout.parts))
+	for i, part := range layout.parts {
+		curves[i] = placedCurves(part, *resolution)
+	}
+
+	if *outputFormat == outputFormatHPGL {
+		plotter := NewHPGLWriter(plotterOptions()...)
+		seq, _ := cutSequence(placed)
+		plotter.AddCutSequence(seq, leads, curves)
+
+		f, err := os.Create(name + ".plt")
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		plotter.Write(f)
+		return nil
+	}
+
+	svgDrawer := NewSVGDrawer(
+		WithOffset(100, -100),
+		WithScale(1),
+		WithSize(300, 300),
+	)
+	svgDrawer.AddPolygon(NewPolygon(outline), "stroke-width", "2", "stroke-dasharray", "5", "stroke", "blue")
+	for i, poly := range placed {
+		if curves[i] != nil {
+			svgDrawer.AddShape(*curves[i], "stroke-width", "1", "stroke", "black")
+		} else {
+			svgDrawer.AddPolygon(poly, "stroke-width", "1", "stroke", "black")
+		}
+	}
 
+	f, err := os.Create(name + ".svg")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+	svgDrawer.Write(f)
 	return nil
 }
 
@@ -165,45 +777,116 @@ type orientation struct {
 	angle     float64
 }
 
-func createOrientations(fig Polygon, angles ...int) []orientation {
-	if len(angles) == 0 {
-		return []orientation{
-			{
-				shape:     fig,
-				angle:     0,
-				occupancy: Discretize(fig, *resolution),
-			},
+// createOrientations returns the orientations of each figure rotated by the angles.
+// The orientations are generated by the pool of workers and shared by the identical
+// figures through the cache. The order of the orientations does not depend on the workers.
+func createOrientations(figs []Polygon, step float64, workers int, angles ...int) [][]orientation {
+	type job struct {
+		fig, angle int
+	}
+
+	orientations := make([][]orientation, len(figs))
+	jobs := make(chan job)
+	for i := range figs {
+		orientations[i] = make([]orientation, max(1, len(angles)))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if len(angles) == 0 {
+					orientations[j.fig][j.angle] = createOrientation(figs[j.fig], step, 0, false)
+				} else {
+					orientations[j.fig][j.angle] = createOrientation(figs[j.fig], step, float64(angles[j.angle]), true)
+				}
+			}
+		}()
+	}
+
+	for i := range figs {
+		for j := range orientations[i] {
+			jobs <- job{fig: i, angle: j}
 		}
 	}
-	var orientations []orientation
-	for _, i := range angles {
-		rotated := fig.Rotate(float64(i))
-		orientations = append(orientations, orientation{
-			shape:     rotated,
-			angle:     float64(i),
-			occupancy: Discretize(rotated, *resolution),
+	close(jobs)
+	wg.Wait()
+
+	for _, o := range orientations {
+		// sort by width
+		sort.SliceStable(o, func(i, j int) bool {
+			return len(o[i].occupancy) < len(o[j].occupancy)
 		})
 	}
 
-	// sort by width
-	sort.Slice(orientations, func(i, j int) bool {
-		return len(orientations[i].occupancy) < len(orientations[j].occupancy)
+	return orientations
+}
+
+// createOrientation returns the orientation of the figure rotated by the angle
+// or the figure itself if it is not rotated
+func createOrientation(fig Polygon, step float64, angle float64, rotate bool) orientation {
+	key := orientationKey(fig, angle, rotate, step, *strips)
+	return orientationCache.Get(key, func() orientation {
+		rotated := fig
+		if rotate {
+			rotated = fig.Rotate(angle)
+		}
+		return orientation{
+			shape:     rotated,
+			angle:     angle,
+			occupancy: discretize(rotated, step),
+		}
 	})
+}
 
-	return orientations
+// discretize decomposes the polygon into the strips of the selected direction
+func discretize(poly Polygon, step float64) OccupancyTable {
+	if *strips == stripsHorizontal {
+		return DiscretizeHorizontal(poly, step)
+	}
+	return Discretize(poly, step)
+}
+
+// usedSheet returns the width and the height of the sheet used by the parts
+// placed within the given length
+func usedSheet(sheet Sheet, length float64) (float64, float64) {
+	if *strips == stripsHorizontal {
+		return float64(sheet.Width), length
+	}
+	return length, float64(sheet.Height)
+}
+
+// newSheet returns the sheet of the size in the units of the dataset scaled to the output units.
+// The length is limited to the whole strips of the step given in the output units.
+// The sheet grows along its width for the vertical strips and along its height for the horizontal ones.
+func newSheet(width, height float32, step float64) Sheet {
+	length := width
+	if *strips == stripsHorizontal {
+		length = height
+	}
+	maxLength := int(float64(length)**scaleOutput/step + epsilon)
+	return Sheet{
+		Width:     width * float32(*scaleOutput),
+		Height:    height * float32(*scaleOutput),
+		MaxLength: float64(maxLength) * step,
+	}
 }
 
 func calculateSheetLength(parts []*Part, step float64) float32 {
 	length := 0.0
 	for _, part := range parts {
-		xoffset := float64(part.Offset.column) * step
+		xoffset := part.Offset.Point(step).X
 		width := float64(len(part.bestOrienation().occupancy)) * step
 		length = max(length, xoffset+width)
 	}
 	return float32(length)
 }
 
-func drawParts(parts []*Part, order []int, file string) error {
+// drawParts draws the parts placed in the order to the svg file and returns
+// the numbers of the placed parts and the used length of the sheet
+func drawParts(group materialGroup, parts []*Part, order []int, file string) ([]int, float64, error) {
 	// TODO: fit svg to full screen and fix scroll bar
 	svgDrawer := NewSVGDrawer(
 		WithOffset(100, -100),
@@ -211,43 +894,59 @@ func drawParts(parts []*Part, order []int, file string) error {
 		WithSize(300, 300),
 	)
 
-	ordered := make([]*Part, len(order))
-	for i, num := range order {
-		ordered[i] = parts[num]
-	}
-
-	fill := NewBottomLeftFill(sheetHeight, maxLength)
-	fill.Run(ordered)
+	nums, fill := placeParts(group, parts, order)
+	ordered := pick(parts, nums)
 
-	length := calculateSheetLength(ordered, *resolution)
+	length := fill.Length()
 	fmt.Println("Length:", length)
 
-	sheetArea := length * float32(sheetHeight)
+	width, height := usedSheet(group.sheet, length)
+	sheetArea := width * height
 	fmt.Println("Area:", sheetArea)
 
 	var figuresArea float64
 	for _, part := range ordered {
 		figuresArea += part.Shape.Area()
 	}
-	fmt.Println("Free area:", float64(sheetArea)-figuresArea)
+	fmt.Println("Free area:", sheetArea-figuresArea)
 
 	svgDrawer.AddLine(
-		float64(length), 0, float64(length), float64(sheetHeight),
+		width, 0, width, height,
 		"stroke-width", "2", "stroke-dasharray", "5", "stroke", "blue",
 	)
-	svgDrawer.AddLine(0, float64(sheetHeight), float64(length), float64(sheetHeight),
+	svgDrawer.AddLine(0, height, width, height,
 		"stroke-width", "2", "stroke-dasharray", "5", "stroke", "blue")
 
-	svgDrawer.DrawCoordSystem(int(length)+25, int(sheetHeight)+25)
+	svgDrawer.DrawCoordSystem(int(width)+25, int(height)+25)
 
+	for _, repeat := range repeats(ordered) {
+		x, y := repeat.axes()
+		for _, pos := range x.lines(width) {
+			svgDrawer.AddLine(pos, 0, pos, height, "stroke-width", "0.5", "stroke", "violet")
+		}
+		for _, pos := range y.lines(height) {
+			svgDrawer.AddLine(0, pos, width, pos, "stroke-width", "0.5", "stroke", "violet")
+		}
+	}
+
+	addOccupancy := svgDrawer.AddPart
+	if *strips == stripsHorizontal {
+		addOccupancy = svgDrawer.AddHorizontalPart
+	}
+
+	placed := make([]Polygon, len(ordered))
 	for i, part := range ordered {
 
-		offsetPoint := NewPoint(float64(part.Offset.column)**resolution, part.Offset.y)
+		offsetPoint := part.Offset.Point(*resolution)
 		color := fmt.Sprintf("#%02x%02x%02x", randRange(100, 255), randRange(100, 255), randRange(100, 255))
-		svgDrawer.AddPart(part.bestOrienation().occupancy, *resolution, offsetPoint,
+		addOccupancy(part.bestOrienation().occupancy, *resolution, offsetPoint,
 			"stroke-width", "1", "stroke", color)
-		svgDrawer.AddPolygon(part.bestOrienation().shape.Offset(offsetPoint),
-			"stroke-width", "1", "stroke", "black")
+		placed[i] = part.bestOrienation().shape.Offset(offsetPoint)
+		if curves := placedCurves(part, *resolution); curves != nil {
+			svgDrawer.AddShape(*curves, "stroke-width", "1", "stroke", "black")
+		} else {
+			svgDrawer.AddPolygon(placed[i], "stroke-width", "1", "stroke", "black")
+		}
 
 		center := part.bestOrienation().shape.Centroid().Offset(offsetPoint)
 		svgDrawer.AddPoint(center, "fill", "blue", "r", "1.5")
@@ -255,19 +954,307 @@ func drawParts(parts []*Part, order []int, file string) error {
 		svgDrawer.AddText(center.Offset(NewPoint(2, 2)), fmt.Sprintf("%d", i), "font-size", "4")
 	}
 
-	svgDrawer.AddPart(fill.getVacancyTable(), *resolution, NewPoint(0, 0), "stroke-width", "1", "stroke", "black")
+	// the preview is drawn from the same sequence as the plotter output
+	seq, common := cutSequence(placed)
+	for _, l := range common {
+		svgDrawer.AddLine(l.Line.Start.X, l.Line.Start.Y, l.Line.End.X, l.Line.End.Y,
+			"stroke-width", "2", "stroke", "orange")
+	}
+
+	if leads.Type != leadNone || leads.Tabs > 0 {
+		for _, contour := range seq.Contours {
+			path := NewCutPath(contour, leads)
+			svgDrawer.AddPolyline(path.LeadIn, "stroke-width", "1", "stroke", "red")
+			svgDrawer.AddPolyline(path.LeadOut, "stroke-width", "1", "stroke", "green")
+			for _, tab := range path.Tabs {
+				svgDrawer.AddPoint(tab, "fill", "red", "r", "1")
+			}
+		}
+	}
+
+	if blf, ok := fill.(*BottomLeftFill); ok {
+		addOccupancy(blf.getVacancyTable(), *resolution, NewPoint(0, 0), "stroke-width", "1", "stroke", "black")
+	}
 
 	f, err := os.Create(file)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return nil, 0, fmt.Errorf("failed to create output file: %w", err)
 	}
 
 	defer f.Close()
 	svgDrawer.Write(f)
 
+	return nums, length, nil
+}
+
+// plotParts plots the parts placed in the order to the hpgl file and returns
+// the numbers of the placed parts and the used length of the sheet
+func plotParts(group materialGroup, parts []*Part, order []int, file string) ([]int, float64, error) {
+	nums, fill := placeParts(group, parts, order)
+	ordered := pick(parts, nums)
+
+	length := fill.Length()
+	fmt.Println("Length:", length)
+
+	plotter := NewHPGLWriter(plotterOptions()...)
+
+	placed := placedShapes(ordered, *resolution)
+
+	seq, _ := cutSequence(placed)
+	fmt.Println("Rapid travel:", seq.Travel)
+
+	plotter.AddSheet(usedSheet(group.sheet, length))
+	curves := make([]*Shape, len(ordered))
+	for i, part := range ordered {
+		curves[i] = placedCurves(part, *resolution)
+	}
+	plotter.AddCutSequence(seq, leads, curves)
+
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	defer f.Close()
+	plotter.Write(f)
+
+	return nums, length, nil
+}
+
+// cutSequence returns the cutting order of the placed parts. If the common lines are enabled,
+// the edges shared by adjacent parts are cut once and the common lines are returned.
+func cutSequence(placed []Polygon) (CutSequence, []CommonLine) {
+	seq := NewCutSequence(placed, NewPoint(0, 0))
+	if !*commonLines {
+		return seq, nil
+	}
+	common := FindCommonLines(placed, commonLineTolerance)
+	fmt.Println("Common lines:", len(common), "Saved cut length:", CommonLinesLength(common))
+	seq.ShareCommonLines(common)
+	return seq, common
+}
+
+// plotterOptions returns the options of the plotter selected by the flags. The plotter units
+// are given per unit of the dataset, so they are scaled back from the output units.
+func plotterOptions() []HPGLWriterOption {
+	opts := []HPGLWriterOption{WithPlotterUnits(*plotterUnits / *scaleOutput)}
+	for layer, pen := range pens {
+		opts = append(opts, WithPen(layer, pen))
+	}
+	return opts
+}
+
+// writeReports writes the report of the layouts, the reports of several layouts are combined
+func writeReports(reports []*Report, started time.Time, name string) error {
+	report := &Report{}
+	switch {
+	case len(reports) == 1:
+		report = reports[0]
+	case len(reports) > 1:
+		report = CombineReports(reports)
+	}
+	report.RuntimeSeconds = time.Since(started).Seconds()
+	return writeReport(report, name)
+}
+
+// writeReport writes the report next to the output as the json and the text files
+func writeReport(report *Report, name string) error {
+	writers := map[string]func(io.Writer) error{
+		name + ".json": report.WriteJSON,
+		name + ".txt":  report.WriteText,
+	}
+	for file, write := range writers {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		if err := write(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write report file: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write report file: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateParts(group materialGroup, parts []*Part, order []int) error {
+	nums, _ := placeParts(group, parts, order)
+	ordered := pick(parts, nums)
+
+	violations := ValidatePlacement(placedShapes(ordered, *resolution), float64(group.sheet.Width), float64(group.sheet.Height))
+	for _, v := range violations {
+		fmt.Println("Violation:", v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("placement is invalid: %d violations", len(violations))
+	}
+
+	fmt.Println("Placement is valid")
 	return nil
 }
 
+// placedShapes returns the shapes of the parts moved to their positions on the sheet
+func placedShapes(parts []*Part, step float64) []Polygon {
+	placed := make([]Polygon, len(parts))
+	for i, part := range parts {
+		offsetPoint := part.Offset.Point(step)
+		placed[i] = part.bestOrienation().shape.Offset(offsetPoint)
+	}
+	return placed
+}
+
+// placedCurves returns the curves of the part moved to its position on the sheet
+// or nil if the part is a polygon
+func placedCurves(part *Part, step float64) *Shape {
+	if part.Curves == nil {
+		return nil
+	}
+	rotate := rotation(part.Shape, part.bestOrienation().angle)
+	offsetPoint := part.Offset.Point(step)
+	placed := part.Curves.Transform(func(pt Point) Point {
+		return rotate(pt).Offset(offsetPoint)
+	})
+	return &placed
+}
+
+// compacts returns true if the placed parts are compacted after the placement,
+// the multi-resolution placement always ends with the compaction at the finest level
+func compacts() bool {
+	return *compact || len(resolutions) > 1
+}
+
+// placeParts places the parts of the group in the given order after the locked parts and
+// packs the filler parts into the space left within the used length.
+// It returns the numbers of the placed parts in the order of the placement.
+func placeParts(group materialGroup, parts []*Part, order []int) ([]int, Placer) {
+	fill := newPlacer(group.sheet, *resolution)
+	if err := lockParts(fill, parts, group.locked); err != nil {
+		panic(err)
+	}
+	order = prioritize(parts, order)
+	unplaced := placeAll(fill, pick(parts, order))
+
+	// the locked parts go first
+	nums := group.lockedNums()
+	for i, num := range order {
+		if !slices.Contains(unplaced, i) {
+			nums = append(nums, num)
+		}
+	}
+
+	if compactor, ok := fill.(Compactor); ok && compacts() {
+		before := fill.Length()
+		moves := compactor.Compact(*compactRotate)
+		fmt.Printf("Compaction: %d moves, length before %f, after %f\n", moves, before, fill.Length())
+	}
+
+	length := fill.Length()
+	for _, num := range fillers(parts, group.nums) {
+		if fill.AddWithin(parts[num], length) {
+			nums = append(nums, num)
+		}
+	}
+
+	return nums, fill
+}
+
+// placeAll places the parts onto the sheet. In the partial mode the parts which do not fit
+// are skipped and their positions in the slice are returned, otherwise the placement panics.
+func placeAll(fill Placer, parts []*Part) []int {
+	if !*partial {
+		fill.Run(parts)
+		return nil
+	}
+
+	var unplaced []int
+	for i, part := range parts {
+		if !fill.Add(part) {
+			unplaced = append(unplaced, i)
+		}
+	}
+	return unplaced
+}
+
+// sheetAcross returns the size of the sheet across its growth
+func sheetAcross(sheet Sheet) float64 {
+	if *strips == stripsHorizontal {
+		return float64(sheet.Width)
+	}
+	return float64(sheet.Height)
+}
+
+// prioritize returns the order with the parts of the higher priority first,
+// the parts of the same priority keep their order
+func prioritize(parts []*Part, order []int) []int {
+	prioritized := slices.Clone(order)
+	sort.SliceStable(prioritized, func(i, j int) bool {
+		return parts[prioritized[i]].Priority > parts[prioritized[j]].Priority
+	})
+	return prioritized
+}
+
+// fillers returns the numbers of the filler parts among the numbers, the parts of the higher priority
+// and then the larger ones go first
+func fillers(parts []*Part, nums []int) []int {
+	nums = slices.DeleteFunc(slices.Clone(nums), func(num int) bool {
+		return !parts[num].Filler
+	})
+	sort.SliceStable(nums, func(i, j int) bool {
+		a, b := parts[nums[i]], parts[nums[j]]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Shape.Area() > b.Shape.Area()
+	})
+	return nums
+}
+
+// fillerReports returns the number of the placed and all parts of each filler piece among the numbers,
+// the mandatory parts of the piece are counted too
+func fillerReports(pieces []PieceOptions, parts []*Part, nums []int, placed []int) []FillerReport {
+	var reports []FillerReport
+	for _, piece := range pieces {
+		if !piece.Filler {
+			continue
+		}
+		report := FillerReport{PieceID: piece.ID, Min: piece.Min}
+		for _, num := range nums {
+			if parts[num].PieceID != piece.ID {
+				continue
+			}
+			report.Total++
+			if slices.Contains(placed, num) {
+				report.Placed++
+			}
+		}
+		if report.Total > 0 {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// newPlacer returns the placement engine selected by the flag
+// for the parts discretized with the step, the options are applied to the bottom-left fill
+func newPlacer(sheet Sheet, step float64, options ...BottomLeftFillOption) Placer {
+	columns := int(sheet.MaxLength/step + epsilon)
+	options = append(options, WithStep(step), WithPlacementRule(placementRules[*placementRule]))
+	if *strips == stripsHorizontal {
+		// the strips go along the width of the sheet
+		if *placement == placementNFP {
+			return NewNoFitPolygonFill(sheet.Width, sheet.MaxLength, nfpCache, WithGrowthAlongY())
+		}
+		return NewBottomLeftFill(sheet.Width, columns, append(options, WithHorizontalStrips())...)
+	}
+
+	if *placement == placementNFP {
+		return NewNoFitPolygonFill(sheet.Height, sheet.MaxLength, nfpCache)
+	}
+	return NewBottomLeftFill(sheet.Height, columns, options...)
+}
+
 func randRange(min, max int) int {
 	return rand.Intn(max-min) + min
 }
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the board of 100x40 with 3 discs of radius 10 and 2 half discs of radius 10
+const curvesDataset = `<nesting>
+<problem>
+<boards><piece id="board0" quantity="1"><component idPolygon="polygon0" type="0" xOffset="0" yOffset="0"/></piece></boards>
+<lot>
+<piece id="disc" quantity="3"><component idPolygon="polygon1" type="0" xOffset="0" yOffset="0"/></piece>
+<piece id="half" quantity="2"><component idPolygon="polygon2" type="0" xOffset="0" yOffset="0"/></piece>
+</lot>
+</problem>
+<polygons>
+<polygon id="polygon0" nVertices="4"><lines>
+<segment n="1" x0="0" x1="0" y0="0" y1="40"/><segment n="2" x0="0" x1="100" y0="40" y1="40"/>
+<segment n="3" x0="100" x1="100" y0="40" y1="0"/><segment n="4" x0="100" x1="0" y0="0" y1="0"/>
+</lines><xMin>0</xMin><xMax>100</xMax><yMin>0</yMin><yMax>40</yMax></polygon>
+<polygon id="polygon1" nVertices="1"><lines>
+<segment n="1" x0="0" x1="0" y0="10" y1="10" curve="arc" xc="10" yc="10" clockwise="true"/>
+</lines><xMin>0</xMin><xMax>20</xMax><yMin>0</yMin><yMax>20</yMax></polygon>
+<polygon id="polygon2" nVertices="2"><lines>
+<segment n="1" x0="0" x1="20" y0="0" y1="0" curve="arc" xc="10" yc="0" clockwise="true"/>
+<segment n="2" x0="20" x1="0" y0="0" y1="0"/>
+</lines><xMin>0</xMin><xMax>20</xMax><yMin>0</yMin><yMax>10</yMax></polygon>
+</polygons>
+</nesting>`
+
+func TestRun_Curves(t *testing.T) {
+	var nesting Nesting
+	assert.NoError(t, xml.Unmarshal([]byte(curvesDataset), &nesting))
+
+	setGlobal(t, scaleOutput, 1)
+	setGlobal(t, resolution, 1)
+	setGlobal(t, &sheets, map[string]Sheet{})
+	setGlobal(t, &orientationCache, NewOrientationCache(""))
+	chdir(t, t.TempDir())
+
+	figures, curves, ids, err := prepareParts(&nesting)
+	assert.NoError(t, err)
+	width, height, err := nesting.GetBoardSizes()
+	assert.NoError(t, err)
+	sheets[""] = newSheet(width, height, *resolution)
+	assert.NoError(t, run(figures, curves, ids))
+
+	svg, err := os.ReadFile("output.svg")
+	assert.NoError(t, err)
+	// each disc is drawn as two halves
+	assert.Equal(t, 8, strings.Count(string(svg), "A10.000000,10.000000"))
+
+	data, err := os.ReadFile("output.json")
+	assert.NoError(t, err)
+	var report Report
+	assert.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 5, report.Placed)
+
+	// the occupancy covers the arcs, so the discs do not overlap
+	var centers []Point
+	for _, part := range report.Parts {
+		if part.Index < 3 {
+			centers = append(centers, NewPoint(
+				(part.Bounds.MinX+part.Bounds.MaxX)/2,
+				(part.Bounds.MinY+part.Bounds.MaxY)/2,
+			))
+		}
+	}
+	assert.Len(t, centers, 3)
+	for i := range centers {
+		for j := i + 1; j < len(centers); j++ {
+			assert.GreaterOrEqual(t, centers[i].Distance(centers[j]), 20.0)
+		}
+	}
+}
+
+// setGlobal sets the global for the test and restores it after the test
+func setGlobal[T any](t *testing.T, global *T, value T) {
+	old := *global
+	*global = value
+	t.Cleanup(func() {
+		*global = old
+	})
+}
+
+// chdir changes the working directory for the test
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		assert.NoError(t, os.Chdir(wd))
+	})
+}
+
+func TestNewSheet(t *testing.T) {
+	setGlobal(t, scaleOutput, 2)
+
+	tests := []struct {
+		name      string
+		strips    string
+		step      float64
+		maxLength float64
+	}{
+		{name: "vertical strips", strips: stripsVertical, step: 2, maxLength: 40},
+		{name: "horizontal strips", strips: stripsHorizontal, step: 2, maxLength: 200},
+		{name: "whole strips of the step", strips: stripsVertical, step: 3, maxLength: 39},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, strips, tt.strips)
+
+			sheet := newSheet(20, 100, tt.step)
+			assert.Equal(t, Sheet{Width: 40, Height: 200, MaxLength: tt.maxLength}, sheet)
+
+			// the parts across the sheet fill it up to its max length
+			across := int(sheet.Height)
+			if tt.strips == stripsHorizontal {
+				across = int(sheet.Width)
+			}
+			fill := newPlacer(sheet, tt.step)
+			for i := 0; i < int(tt.maxLength/tt.step); i++ {
+				assert.True(t, fill.Add(&Part{Orientations: []orientation{{occupancy: NewRectanlePart(across, 1)}}}))
+			}
+			assert.False(t, fill.Add(&Part{Orientations: []orientation{{occupancy: NewRectanlePart(across, 1)}}}))
+		})
+	}
+}
+
+func TestFillerReports(t *testing.T) {
+	pieces := []PieceOptions{{ID: "a", Filler: true, Min: 1}, {ID: "b"}, {ID: "c", Filler: true}}
+	job := &Job{Pieces: pieces}
+	parts := []*Part{{PieceID: "a"}, {PieceID: "a"}, {PieceID: "a"}, {PieceID: "b"}, {PieceID: "c"}}
+	job.Apply(parts)
+
+	// the piece c is not in the group
+	got := fillerReports(pieces, parts, []int{0, 1, 2, 3}, []int{0, 2, 3})
+	assert.Equal(t, []FillerReport{{PieceID: "a", Min: 1, Placed: 2, Total: 3}}, got)
+}
+
+func TestWriteGroup_FillerMinNotMet(t *testing.T) {
+	setGlobal(t, resolution, 1)
+	setGlobal(t, partial, true)
+	setGlobal(t, &job, &Job{Pieces: []PieceOptions{{ID: "a", Filler: true, Min: 2}}})
+	chdir(t, t.TempDir())
+
+	shape := NewPolygon(NewRectangle(0, 0, 2, 2))
+	var parts []*Part
+	for i := 0; i < 3; i++ {
+		parts = append(parts, &Part{
+			Orientations: []orientation{{shape: shape, occupancy: NewRectanlePart(2, 2)}},
+			Shape:        shape,
+			PieceID:      "a",
+		})
+	}
+	job.Apply(parts)
+
+	// the sheet fits only one of the two mandatory parts
+	group := materialGroup{sheet: Sheet{Width: 2, Height: 2, MaxLength: 2}, nums: []int{0, 1, 2}}
+	report, err := writeGroup(group, parts, group.remaining(parts))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{1}, report.Leftovers)
+	assert.Equal(t, []FillerReport{{PieceID: "a", Min: 2, Placed: 1, Total: 3}}, report.Fillers)
+	// the unplaced mandatory part and the unplaced optional one
+	assert.Equal(t, 2, report.Unplaced)
+}
+
+func TestCutSequence(t *testing.T) {
+	placed := []Polygon{
+		NewPolygon(NewRectangle(0, 0, 2, 2)),
+		NewPolygon(NewRectangle(2, 0, 2, 2)),
+	}
+
+	tests := []struct {
+		name        string
+		commonLines bool
+		shared      int
+	}{
+		{name: "without the common lines"},
+		{name: "with the common lines", commonLines: true, shared: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, commonLines, tt.commonLines)
+
+			seq, common := cutSequence(placed)
+			assert.Len(t, common, tt.shared)
+			var shared int
+			for _, contour := range seq.Contours {
+				shared += len(contour.Shared)
+			}
+			assert.Equal(t, tt.shared, shared)
+		})
+	}
+}
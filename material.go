@@ -0,0 +1,76 @@
+package main
+
+import "slices"
+
+// Sheet is the stock the parts are nested onto, the size is in the output units
+type Sheet struct {
+	Width  float32
+	Height float32
+	// the maximum length of the sheet the parts are placed within
+	MaxLength float64
+}
+
+// materialGroup is the parts of one material nested onto the sheet of the material
+// independently of the other groups
+type materialGroup struct {
+	material string
+	sheet    Sheet
+	// the numbers of the parts of the group in the dataset
+	nums []int
+	// the locked parts of the group
+	locked []LockedPart
+}
+
+// groupParts splits the parts by their material, the groups go in the order of their first parts
+func groupParts(parts []*Part, locked []LockedPart) []materialGroup {
+	var groups []materialGroup
+	for i, part := range parts {
+		g := slices.IndexFunc(groups, func(group materialGroup) bool {
+			return group.material == part.Material
+		})
+		if g == -1 {
+			groups = append(groups, materialGroup{material: part.Material})
+			g = len(groups) - 1
+		}
+		groups[g].nums = append(groups[g].nums, i)
+	}
+
+	for _, l := range locked {
+		for g := range groups {
+			if slices.Contains(groups[g].nums, l.Part) {
+				groups[g].locked = append(groups[g].locked, l)
+			}
+		}
+	}
+	return groups
+}
+
+// lockedNums returns the numbers of the locked parts of the group
+func (g materialGroup) lockedNums() []int {
+	nums := make([]int, len(g.locked))
+	for i, l := range g.locked {
+		nums[i] = l.Part
+	}
+	return nums
+}
+
+// remaining returns the numbers of the parts of the group nested onto the sheet
+// besides the locked and the filler ones
+func (g materialGroup) remaining(parts []*Part) []int {
+	var nums []int
+	for _, num := range g.nums {
+		if !slices.Contains(g.lockedNums(), num) && !parts[num].Filler {
+			nums = append(nums, num)
+		}
+	}
+	return nums
+}
+
+// fileName returns the name of the file of the group, the material is appended
+// to the names of the files of the parts with the material
+func (g materialGroup) fileName(name string) string {
+	if g.material == "" {
+		return name
+	}
+	return name + "-" + g.material
+}
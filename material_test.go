@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupParts(t *testing.T) {
+	parts := []*Part{
+		{Material: "steel"},
+		{},
+		{Material: "steel", Filler: true},
+		{Material: "wood"},
+		{},
+	}
+	locked := []LockedPart{{Part: 4, X: 1}, {Part: 0}}
+
+	groups := groupParts(parts, locked)
+	assert.Equal(t, []materialGroup{
+		{material: "steel", nums: []int{0, 2}, locked: []LockedPart{{Part: 0}}},
+		{material: "", nums: []int{1, 4}, locked: []LockedPart{{Part: 4, X: 1}}},
+		{material: "wood", nums: []int{3}},
+	}, groups)
+
+	assert.Equal(t, []int(nil), groups[0].remaining(parts))
+	assert.Equal(t, []int{1}, groups[1].remaining(parts))
+	assert.Equal(t, "output-steel", groups[0].fileName("output"))
+	assert.Equal(t, "output", groups[1].fileName("output"))
+}
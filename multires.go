@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+const (
+	// the number of the best orders of the coarse resolution re-run at the finer one
+	refineCandidates = 5
+	// the max number of the passes of the local search
+	localSearchPasses = 3
+)
+
+// orderFitness returns the fitness of the order of the parts, the greater the better
+type orderFitness func(order []int) float32
+
+// refineOrders re-evaluates the candidate orders at each level from coarse to fine.
+// After each level only the better half of the candidates is kept,
+// so the finest level evaluates the fewest orders. It returns the best order
+// and its fitness at the last level.
+func refineOrders(candidates [][]int, levels []orderFitness) ([]int, float32) {
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+
+	type evaluated struct {
+		order   []int
+		fitness float32
+	}
+
+	orders := make([]evaluated, len(candidates))
+	for i, order := range candidates {
+		orders[i] = evaluated{order: order}
+	}
+
+	for level, fitness := range levels {
+		for i := range orders {
+			orders[i].fitness = fitness(orders[i].order)
+		}
+		sort.SliceStable(orders, func(i, j int) bool {
+			return orders[i].fitness > orders[j].fitness
+		})
+		if level < len(levels)-1 {
+			orders = orders[:(len(orders)+1)/2]
+		}
+	}
+
+	return orders[0].order, orders[0].fitness
+}
+
+// localSearch improves the order by swapping the adjacent parts
+// while the swaps improve the fitness
+func localSearch(order []int, fitness orderFitness, passes int) ([]int, float32) {
+	best := slices.Clone(order)
+	bestFitness := fitness(best)
+
+	for pass := 0; pass < passes; pass++ {
+		improved := false
+		for i := 0; i < len(best)-1; i++ {
+			candidate := slices.Clone(best)
+			swap(candidate, i, i+1)
+			if f := fitness(candidate); f > bestFitness {
+				best, bestFitness = candidate, f
+				improved = true
+			}
+		}
+		if !improved {
+			break
+		}
+		fmt.Printf("Local search pass %d, fitness: %f\n", pass, bestFitness)
+	}
+
+	return best, bestFitness
+}
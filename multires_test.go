@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sortedness returns the number of the adjacent parts in the ascending order
+func sortedness(order []int) float32 {
+	var fitness float32
+	for i := 0; i < len(order)-1; i++ {
+		if order[i] < order[i+1] {
+			fitness++
+		}
+	}
+	return fitness
+}
+
+func TestRefineOrders(t *testing.T) {
+	candidates := [][]int{{2, 1, 0}, {0, 2, 1}, {0, 1, 2}, {1, 0, 2}}
+
+	var evaluations []int
+	coarse := func(order []int) float32 {
+		evaluations = append(evaluations, 0)
+		// the coarse level tells apart only the first part
+		if order[0] == 0 {
+			return 1
+		}
+		return 0
+	}
+	fine := func(order []int) float32 {
+		evaluations = append(evaluations, 1)
+		return sortedness(order)
+	}
+
+	got, fitness := refineOrders(candidates, []orderFitness{coarse, fine})
+	assert.Equal(t, []int{0, 1, 2}, got)
+	assert.Equal(t, float32(2), fitness)
+	// the fine level evaluates only the better half of the candidates
+	assert.Equal(t, []int{0, 0, 0, 0, 1, 1}, evaluations)
+}
+
+func TestLocalSearch(t *testing.T) {
+	got, fitness := localSearch([]int{1, 0, 3, 2, 4}, sortedness, localSearchPasses)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, got)
+	assert.Equal(t, float32(4), fitness)
+}
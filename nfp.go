@@ -0,0 +1,692 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+// the max depth the placed parts may overlap each other
+const nfpTolerance = 1e-4
+
+// NoFitPolygonFill places a sequence of parts at the bottom-left feasible positions.
+// The positions are found with the no-fit polygons of the placed parts and
+// the inner-fit polygon of the sheet instead of the strips of the parts.
+// https://en.wikipedia.org/wiki/Nesting_algorithm
+type NoFitPolygonFill struct {
+	// the height of the sheet
+	height float64
+	// the maximum length of the sheet
+	maxLength float64
+	// true if the sheet grows along y, then the height is the width of the sheet
+	horizontal bool
+	cache      *NFPCache
+	placed     []nfpPlacement
+}
+
+type nfpPlacement struct {
+	part        *Part
+	orientation *orientation
+	position    Point
+	// the shape of the orientation moved to the position
+	placed Polygon
+	// true if the part is locked at the position
+	locked bool
+}
+
+type NoFitPolygonFillOption func(*NoFitPolygonFill)
+
+// WithGrowthAlongY places the parts from bottom to top and then from left to right,
+// so the sheet of the fixed width grows along y
+func WithGrowthAlongY() NoFitPolygonFillOption {
+	return func(r *NoFitPolygonFill) {
+		r.horizontal = true
+	}
+}
+
+func NewNoFitPolygonFill(height float32, maxLength float64, cache *NFPCache, options ...NoFitPolygonFillOption) *NoFitPolygonFill {
+	if cache == nil {
+		cache = NewNFPCache()
+	}
+	fill := &NoFitPolygonFill{
+		height:    float64(height),
+		maxLength: maxLength,
+		cache:     cache,
+	}
+
+	for _, option := range options {
+		option(fill)
+	}
+
+	return fill
+}
+
+// Run places the parts one by one at the leftmost and then the lowest position
+// among all orientations of the part
+func (r *NoFitPolygonFill) Run(parts []*Part) {
+	for _, part := range parts {
+		if !r.Add(part) {
+			panic(fmt.Sprintf("all parts cannot be placed, length %f reached", r.maxLength))
+		}
+	}
+}
+
+func (r *NoFitPolygonFill) Add(part *Part) bool {
+	return r.AddWithin(part, r.maxLength)
+}
+
+func (r *NoFitPolygonFill) AddWithin(part *Part, length float64) bool {
+	best, bestPosition := -1, Point{}
+	for i := range part.Orientations {
+		position, ok := r.position(&part.Orientations[i])
+		if !ok {
+			continue
+		}
+		_, _, maxx, maxy := part.Orientations[i].shape.Bounds()
+		end := position.X + maxx
+		if r.horizontal {
+			end = position.Y + maxy
+		}
+		if end > length+epsilon {
+			continue
+		}
+		if best == -1 || r.compare(position, bestPosition) < 0 {
+			best, bestPosition = i, position
+		}
+	}
+
+	if best == -1 {
+		return false
+	}
+
+	r.placed = append(r.placed, r.placement(part, best, bestPosition))
+	return true
+}
+
+func (r *NoFitPolygonFill) placement(part *Part, orientationNum int, position Point) nfpPlacement {
+	part.Offset = Offset{x: position.X, y: position.Y}
+	part.BestOrientationNum = orientationNum
+	return nfpPlacement{
+		part:        part,
+		orientation: &part.Orientations[orientationNum],
+		position:    position,
+		placed:      part.Orientations[orientationNum].shape.Offset(position),
+	}
+}
+
+func (r *NoFitPolygonFill) Compact(rotate bool) int {
+	moves := 0
+	for pass := 0; pass < compactionPasses; pass++ {
+		moved := false
+		for i, current := range r.placed {
+			if current.locked {
+				continue
+			}
+			// the part is compared only with the other placed parts
+			others := r.placed
+			r.placed = slices.Delete(slices.Clone(others), i, i+1)
+
+			best, bestPosition := -1, current.position
+			for num := range current.part.Orientations {
+				o := &current.part.Orientations[num]
+				if o != current.orientation && !rotate {
+					continue
+				}
+				position, ok := r.position(o)
+				if ok && r.compare(position, bestPosition) < 0 && !closeTo(position, bestPosition) {
+					best, bestPosition = num, position
+				}
+			}
+
+			r.placed = others
+			if best == -1 {
+				continue
+			}
+			r.placed[i] = r.placement(current.part, best, bestPosition)
+			moved = true
+			moves++
+		}
+		if !moved {
+			break
+		}
+	}
+	return moves
+}
+
+func (r *NoFitPolygonFill) Lock(part *Part, position Point) error {
+	placement := nfpPlacement{
+		part:        part,
+		orientation: &part.Orientations[part.BestOrientationNum],
+		position:    position,
+		placed:      part.bestOrienation().shape.Offset(position),
+		locked:      true,
+	}
+	if !r.fits(placement.placed) {
+		return fmt.Errorf("locked part at %v overlaps the locked parts or leaves the sheet", position)
+	}
+
+	part.Offset = Offset{x: position.X, y: position.Y}
+	r.placed = append(r.placed, placement)
+	return nil
+}
+
+// closeTo returns true if the positions differ less than the tolerance
+func closeTo(a, b Point) bool {
+	return math.Abs(a.X-b.X) < nfpTolerance && math.Abs(a.Y-b.Y) < nfpTolerance
+}
+
+func (r *NoFitPolygonFill) Length() float64 {
+	var length float64
+	for _, p := range r.placed {
+		_, _, maxx, maxy := p.orientation.shape.Bounds()
+		if r.horizontal {
+			length = max(length, p.position.Y+maxy)
+		} else {
+			length = max(length, p.position.X+maxx)
+		}
+	}
+	return length
+}
+
+// sheet returns the max size of the sheet along x and y
+func (r *NoFitPolygonFill) sheet() (float64, float64) {
+	if r.horizontal {
+		return r.height, r.maxLength
+	}
+	return r.maxLength, r.height
+}
+
+// compare orders the positions along the growth of the sheet and then across it
+func (r *NoFitPolygonFill) compare(a, b Point) int {
+	if r.horizontal {
+		return comparePositions(NewPoint(a.Y, a.X), NewPoint(b.Y, b.X))
+	}
+	return comparePositions(a, b)
+}
+
+// comparePositions orders the positions from left to right and then from bottom to top
+func comparePositions(a, b Point) int {
+	if c := cmp.Compare(toFixed(a.X, 4), toFixed(b.X, 4)); c != 0 {
+		return c
+	}
+	return cmp.Compare(toFixed(a.Y, 4), toFixed(b.Y, 4))
+}
+
+// position returns the bottom-left position of the orientation which does not overlap
+// the placed parts. The feasible positions lie inside the inner-fit polygon and outside
+// all no-fit polygons, so the bottom-left one is a vertex of these polygons
+// or an intersection of their edges.
+func (r *NoFitPolygonFill) position(o *orientation) (Point, bool) {
+	minx, miny, maxx, maxy := o.shape.Bounds()
+	length, height := r.sheet()
+	// the inner-fit polygon of the rectangular sheet is a rectangle
+	ifp := NewRectangle(-minx, -miny, height-maxy+miny, length-maxx+minx)
+	if ifp[2].X < ifp[0].X-nfpTolerance || ifp[2].Y < ifp[0].Y-nfpTolerance {
+		return Point{}, false
+	}
+
+	var nfps []Polygon
+	for _, p := range r.placed {
+		for _, nfp := range r.cache.Get(p.orientation, o) {
+			nfps = append(nfps, nfp.Offset(p.position))
+		}
+	}
+
+	var edges []Line
+	candidates := slices.Clone(ifp[:4])
+	for _, nfp := range nfps {
+		for _, ring := range nfp.rings() {
+			candidates = append(candidates, ring...)
+			edges = append(edges, ringEdges(ring)...)
+		}
+	}
+	candidates = append(candidates, edgeIntersections(append(edges, ringEdges(ifp)...))...)
+
+	slices.SortFunc(candidates, r.compare)
+
+	bounds := make([][4]float64, len(nfps))
+	for i, nfp := range nfps {
+		bounds[i][0], bounds[i][1], bounds[i][2], bounds[i][3] = nfp.Bounds()
+	}
+
+	for _, pt := range candidates {
+		if pt.X < ifp[0].X-nfpTolerance || pt.X > ifp[2].X+nfpTolerance ||
+			pt.Y < ifp[0].Y-nfpTolerance || pt.Y > ifp[2].Y+nfpTolerance {
+			continue
+		}
+
+		feasible := true
+		for i, nfp := range nfps {
+			if pt.X <= bounds[i][0] || pt.X >= bounds[i][2] || pt.Y <= bounds[i][1] || pt.Y >= bounds[i][3] {
+				continue
+			}
+			if strictlyContains(nfp, pt) {
+				feasible = false
+				break
+			}
+		}
+		if !feasible {
+			continue
+		}
+		if position, ok := r.roundPosition(o.shape, pt); ok {
+			return position, true
+		}
+	}
+	return Point{}, false
+}
+
+// roundPosition returns the position rounded to the precision of the offsets,
+// so the part touching the placed parts does not overlap them after the rounding
+func (r *NoFitPolygonFill) roundPosition(shape Polygon, pt Point) (Point, bool) {
+	const precision = 1e-4
+	// the nearest rounding goes first, the division may put the exact position
+	// right below the multiple of the precision
+	xs := []float64{pt.X, math.Floor(pt.X/precision) * precision, math.Ceil(pt.X/precision) * precision}
+	ys := []float64{pt.Y, math.Floor(pt.Y/precision) * precision, math.Ceil(pt.Y/precision) * precision}
+	for _, x := range xs {
+		for _, y := range ys {
+			position := NewPoint(toFixed(x, 4), toFixed(y, 4))
+			if r.fits(shape.Offset(position)) {
+				return position, true
+			}
+		}
+	}
+	return Point{}, false
+}
+
+// fits returns true if the moved shape does not overlap the placed parts
+func (r *NoFitPolygonFill) fits(moved Polygon) bool {
+	length, height := r.sheet()
+	minx, miny, maxx, maxy := moved.Bounds()
+	if minx < -epsilon || miny < -epsilon || maxx > length+epsilon || maxy > height+epsilon {
+		return false
+	}
+	for _, p := range r.placed {
+		if overlaps(moved, p.placed) {
+			return false
+		}
+	}
+	return true
+}
+
+// strictlyContains returns true if the point lies inside the polygon
+// farther than the tolerance from its boundary
+func strictlyContains(poly Polygon, pt Point) bool {
+	if !poly.Contains(pt) {
+		return false
+	}
+	for _, ring := range poly.rings() {
+		for i := 0; i < len(ring)-1; i++ {
+			if distanceToSegment(pt, ring[i], ring[i+1]) < nfpTolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func ringEdges(ring Ring) []Line {
+	edges := make([]Line, 0, len(ring))
+	for i := 0; i < len(ring)-1; i++ {
+		edges = append(edges, Line{Start: ring[i], End: ring[i+1]})
+	}
+	return edges
+}
+
+// edgeIntersections returns the points where the edges cross each other.
+// The edges are swept from left to right, so only the edges overlapping along x are compared.
+func edgeIntersections(edges []Line) []Point {
+	slices.SortFunc(edges, func(a, b Line) int {
+		return cmp.Compare(min(a.Start.X, a.End.X), min(b.Start.X, b.End.X))
+	})
+
+	var points []Point
+	for i, e := range edges {
+		maxx := max(e.Start.X, e.End.X)
+		miny, maxy := min(e.Start.Y, e.End.Y), max(e.Start.Y, e.End.Y)
+		for _, o := range edges[i+1:] {
+			if min(o.Start.X, o.End.X) > maxx {
+				break
+			}
+			if min(o.Start.Y, o.End.Y) > maxy || max(o.Start.Y, o.End.Y) < miny {
+				continue
+			}
+			for _, t := range intersectionParams(e, o) {
+				points = append(points, interpolate(e.Start, e.End, t))
+			}
+		}
+	}
+	return points
+}
+
+// NFPCache stores the no-fit polygons of the pairs of orientations,
+// so they are computed once for all placements made by the genetic algorithm
+type NFPCache struct {
+	mu    sync.Mutex
+	keys  map[*orientation]string
+	polys map[[2]string][]Polygon
+}
+
+func NewNFPCache() *NFPCache {
+	return &NFPCache{
+		keys:  make(map[*orientation]string),
+		polys: make(map[[2]string][]Polygon),
+	}
+}
+
+// Get returns the no-fit polygon of the orbiting orientation around the stationary one
+func (c *NFPCache) Get(stationary, orbiting *orientation) []Polygon {
+	c.mu.Lock()
+	key := [2]string{c.key(stationary), c.key(orbiting)}
+	polys, exists := c.polys[key]
+	c.mu.Unlock()
+	if exists {
+		return polys
+	}
+
+	polys = NoFitPolygon(stationary.shape, orbiting.shape)
+
+	c.mu.Lock()
+	c.polys[key] = polys
+	c.mu.Unlock()
+	return polys
+}
+
+// key returns the key of the orientation shape, the parts of the same shape share the key
+func (c *NFPCache) key(o *orientation) string {
+	key, exists := c.keys[o]
+	if !exists {
+		key = fmt.Sprint(o.shape.outerRing, o.shape.innerRings)
+		c.keys[o] = key
+	}
+	return key
+}
+
+// NoFitPolygon returns the positions of the orbiting polygon at which it overlaps
+// the stationary one, that is the Minkowski sum of the stationary polygon and
+// the negated orbiting polygon. Both polygons are decomposed into convex pieces,
+// the sums of the convex pieces are the convex hulls of the sums of their vertices.
+// The holes of the result are the positions inside the holes of the polygons.
+// https://en.wikipedia.org/wiki/Minkowski_addition
+func NoFitPolygon(stationary, orbiting Polygon) []Polygon {
+	negated := orbiting.Transform(func(pt Point) Point {
+		return NewPoint(-pt.X, -pt.Y)
+	})
+
+	stationaryPieces := ConvexPieces(stationary)
+	orbitingPieces := ConvexPieces(negated)
+
+	sums := make([]Polygon, 0, len(stationaryPieces)*len(orbitingPieces))
+	for _, a := range stationaryPieces {
+		for _, b := range orbitingPieces {
+			var points []Point
+			for _, pa := range a[:len(a)-1] {
+				for _, pb := range b[:len(b)-1] {
+					points = append(points, NewPoint(pa.X+pb.X, pa.Y+pb.Y))
+				}
+			}
+			sums = append(sums, NewPolygon(convexHull(points)))
+		}
+	}
+	return UnionAll(sums)
+}
+
+// Transform returns the polygon with the points mapped by the function
+func (p Polygon) Transform(fn func(Point) Point) Polygon {
+	transform := func(ring Ring) Ring {
+		transformed := make(Ring, len(ring))
+		for i, pt := range ring {
+			transformed[i] = fn(pt)
+		}
+		return transformed
+	}
+
+	var inners []Ring
+	for _, innerRing := range p.innerRings {
+		inners = append(inners, transform(innerRing))
+	}
+	return NewPolygon(transform(p.outerRing), inners...)
+}
+
+// ConvexPieces decomposes the polygon into clockwise convex rings.
+// The polygon is triangulated and the adjacent pieces are merged while
+// the merged piece is convex (Hertel-Mehlhorn).
+// https://en.wikipedia.org/wiki/Polygon_partition
+func ConvexPieces(p Polygon) []Ring {
+	outer := openRing(p.outerRing, true)
+	if len(p.innerRings) == 0 && isConvex(outer) {
+		return []Ring{closeRing(outer)}
+	}
+
+	pieces := triangulate(bridgeHoles(p))
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(pieces) && !merged; i++ {
+			for j := i + 1; j < len(pieces); j++ {
+				piece, ok := mergePieces(pieces[i], pieces[j])
+				if !ok || !isConvex(piece) {
+					continue
+				}
+				pieces[i] = piece
+				pieces = slices.Delete(pieces, j, j+1)
+				merged = true
+				break
+			}
+		}
+	}
+
+	rings := make([]Ring, len(pieces))
+	for i, piece := range pieces {
+		rings[i] = closeRing(piece)
+	}
+	return rings
+}
+
+// openRing returns the points of the ring without the closing one,
+// ordered clockwise or counter-clockwise
+func openRing(ring Ring, clockwise bool) []Point {
+	points := slices.Clone(ring)
+	if ring.IsClosed() {
+		points = points[:len(points)-1]
+	}
+	if (closeRing(points).Area() > 0) != clockwise {
+		slices.Reverse(points)
+	}
+	return points
+}
+
+// isConvex returns true if the clockwise points do not turn counter-clockwise
+func isConvex(points []Point) bool {
+	n := len(points)
+	for i := range points {
+		if turn(points[(i+n-1)%n], points[i], points[(i+1)%n]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bridgeHoles joins the counter-clockwise holes to the clockwise outer ring
+// with the bridges going to the outer ring and back, so the polygon becomes a single ring
+func bridgeHoles(p Polygon) []Point {
+	points := openRing(p.outerRing, true)
+
+	holes := make([][]Point, len(p.innerRings))
+	for i, innerRing := range p.innerRings {
+		holes[i] = openRing(innerRing, false)
+	}
+	// the holes are joined from right to left, so the bridges do not cross the joined holes
+	rightmost := func(hole []Point) int {
+		idx := 0
+		for i, pt := range hole {
+			if pt.X > hole[idx].X {
+				idx = i
+			}
+		}
+		return idx
+	}
+	slices.SortFunc(holes, func(a, b []Point) int {
+		return cmp.Compare(b[rightmost(b)].X, a[rightmost(a)].X)
+	})
+
+	for h, hole := range holes {
+		m := rightmost(hole)
+		from := hole[m]
+
+		// the nearest vertex of the ring visible from the hole
+		order := make([]int, len(points))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			return cmp.Compare(from.Distance(points[a]), from.Distance(points[b]))
+		})
+
+		bridge := -1
+		for _, i := range order {
+			if bridgeVisible(p, from, points[i], points, holes[h:]) {
+				bridge = i
+				break
+			}
+		}
+		if bridge == -1 {
+			// the hole cannot be joined, the piece covers it conservatively
+			continue
+		}
+
+		joined := make([]Point, 0, len(points)+len(hole)+2)
+		joined = append(joined, points[:bridge+1]...)
+		joined = append(joined, hole[m:]...)
+		joined = append(joined, hole[:m+1]...)
+		joined = append(joined, points[bridge:]...)
+		points = joined
+	}
+	return points
+}
+
+// bridgeVisible returns true if the bridge between the points runs through the polygon
+// without crossing the ring and the holes
+func bridgeVisible(p Polygon, from, to Point, ring []Point, holes [][]Point) bool {
+	if from == to || !p.Contains(interpolate(from, to, 0.5)) {
+		return false
+	}
+	bridge := Line{Start: from, End: to}
+	for _, points := range append([][]Point{ring}, holes...) {
+		for _, e := range ringEdges(closeRing(points)) {
+			if e.Crosses(bridge) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// triangulate decomposes the clockwise ring into triangles by clipping the ears.
+// https://en.wikipedia.org/wiki/Polygon_triangulation#Ear_clipping_method
+func triangulate(points []Point) [][]Point {
+	points = slices.Clone(points)
+
+	var triangles [][]Point
+	for len(points) > 3 {
+		n := len(points)
+		clipped := false
+		for i := range points {
+			prev, cur, next := points[(i+n-1)%n], points[i], points[(i+1)%n]
+			switch turn(prev, cur, next) {
+			case 0:
+				// the collinear vertex does not form a triangle
+			case 1:
+				continue
+			default:
+				if !isEar(points, prev, cur, next) {
+					continue
+				}
+				triangles = append(triangles, []Point{prev, cur, next})
+			}
+			points = slices.Delete(points, i, i+1)
+			clipped = true
+			break
+		}
+
+		if !clipped {
+			// the degenerate remainder is covered by its hull conservatively
+			return append(triangles, openRing(convexHull(points), true))
+		}
+	}
+
+	if len(points) == 3 && turn(points[0], points[1], points[2]) < 0 {
+		triangles = append(triangles, points)
+	}
+	return triangles
+}
+
+// isEar returns true if no other vertex of the ring lies inside the triangle
+func isEar(points []Point, a, b, c Point) bool {
+	for _, pt := range points {
+		if pt == a || pt == b || pt == c {
+			continue
+		}
+		if turn(a, b, pt) <= 0 && turn(b, c, pt) <= 0 && turn(c, a, pt) <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergePieces joins the clockwise pieces sharing an edge
+func mergePieces(a, b []Point) ([]Point, bool) {
+	for i := range a {
+		start, end := a[i], a[(i+1)%len(a)]
+		for j := range b {
+			if b[j] != end || b[(j+1)%len(b)] != start {
+				continue
+			}
+			// a from the end of the shared edge to its start, then b between them
+			merged := make([]Point, 0, len(a)+len(b)-2)
+			for k := 1; k <= len(a); k++ {
+				merged = append(merged, a[(i+k)%len(a)])
+			}
+			for k := 2; k < len(b); k++ {
+				merged = append(merged, b[(j+k)%len(b)])
+			}
+			return merged, true
+		}
+	}
+	return nil, false
+}
+
+// convexHull returns the clockwise convex hull of the points.
+// https://en.wikibooks.org/wiki/Algorithm_Implementation/Geometry/Convex_hull/Monotone_chain
+func convexHull(points []Point) Ring {
+	points = slices.Clone(points)
+	slices.SortFunc(points, func(a, b Point) int {
+		if c := cmp.Compare(a.X, b.X); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Y, b.Y)
+	})
+	points = slices.Compact(points)
+	if len(points) < 3 {
+		return closeRing(points)
+	}
+
+	// the lower and the upper chains turning clockwise
+	var hull []Point
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, pt := range points {
+			for len(hull) >= start+2 && cross(
+				NewPoint(hull[len(hull)-1].X-hull[len(hull)-2].X, hull[len(hull)-1].Y-hull[len(hull)-2].Y),
+				NewPoint(pt.X-hull[len(hull)-2].X, pt.Y-hull[len(hull)-2].Y),
+			) >= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, pt)
+		}
+		hull = hull[:len(hull)-1]
+		slices.Reverse(points)
+	}
+	return closeRing(hull)
+}
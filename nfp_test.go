@@ -0,0 +1,198 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvexPieces(t *testing.T) {
+	tests := []struct {
+		name string
+		poly Polygon
+	}{
+		{
+			name: "square",
+			poly: NewPolygon(NewRectangle(0, 0, 2, 2)),
+		},
+		{
+			name: "L-shape",
+			poly: NewPolygon(Ring{{0, 0}, {0, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 0}, {0, 0}}),
+		},
+		{
+			name: "square with hole",
+			poly: NewPolygon(NewRectangle(0, 0, 6, 6), NewRectangle(2, 2, 2, 2)),
+		},
+		{
+			name: "counter-clockwise star",
+			poly: NewPolygon(Ring{{0, 0}, {3, 1}, {6, 0}, {5, 3}, {6, 6}, {3, 5}, {0, 6}, {1, 3}, {0, 0}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pieces := ConvexPieces(tt.poly)
+
+			var area float64
+			for _, piece := range pieces {
+				assert.True(t, isConvex(piece[:len(piece)-1]))
+				assert.Greater(t, piece.Area(), 0.0)
+				area += piece.Area()
+			}
+			assert.InDelta(t, math.Abs(tt.poly.Area()), area, epsilon)
+		})
+	}
+}
+
+func TestNoFitPolygon(t *testing.T) {
+	square := NewPolygon(NewRectangle(0, 0, 2, 2))
+
+	got := NoFitPolygon(square, square)
+	assert.Len(t, got, 1)
+	assert.InDelta(t, 16, got[0].Area(), epsilon)
+	minx, miny, maxx, maxy := got[0].Bounds()
+	assert.Equal(t, []float64{-2, -2, 2, 2}, []float64{minx, miny, maxx, maxy})
+
+	// the small square fits into the hole of the frame
+	frame := NewPolygon(NewRectangle(0, 0, 6, 6), NewRectangle(2, 2, 2, 2))
+	got = NoFitPolygon(frame, NewPolygon(NewRectangle(0, 0, 1, 1)))
+	assert.Len(t, got, 1)
+	assert.Len(t, got[0].innerRings, 1)
+	assert.InDelta(t, 49-1, got[0].Area(), epsilon)
+}
+
+func TestNoFitPolygonFill(t *testing.T) {
+	newPart := func(poly Polygon) *Part {
+		return &Part{Shape: poly, Orientations: []orientation{{shape: poly}}}
+	}
+
+	tests := []struct {
+		name     string
+		height   float32
+		options  []NoFitPolygonFillOption
+		parts    []Polygon
+		expected []Point
+		length   float64
+	}{
+		{
+			name:   "squares",
+			height: 4,
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+			},
+			expected: []Point{{0, 0}, {0, 2}, {2, 0}},
+			length:   4,
+		},
+		{
+			name:    "squares growing along y",
+			height:  4,
+			options: []NoFitPolygonFillOption{WithGrowthAlongY()},
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+			},
+			expected: []Point{{0, 0}, {2, 0}, {0, 2}},
+			length:   4,
+		},
+		{
+			name:   "triangles",
+			height: 2,
+			parts: []Polygon{
+				NewPolygon(Ring{{0, 0}, {0, 2}, {2, 0}, {0, 0}}),
+				NewPolygon(Ring{{0, 2}, {2, 2}, {2, 0}, {0, 2}}),
+			},
+			expected: []Point{{0, 0}, {0, 0}},
+			length:   2,
+		},
+		{
+			name:   "square in hole",
+			height: 6,
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 6, 6), NewRectangle(2, 2, 2, 2)),
+				NewPolygon(NewRectangle(0, 0, 1, 1)),
+			},
+			expected: []Point{{0, 0}, {2, 2}},
+			length:   6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := make([]*Part, len(tt.parts))
+			for i, poly := range tt.parts {
+				parts[i] = newPart(poly)
+			}
+
+			fill := NewNoFitPolygonFill(tt.height, 100, nil, tt.options...)
+			fill.Run(parts)
+
+			for i, part := range parts {
+				assert.Equal(t, tt.expected[i], part.Offset.Point(1))
+			}
+			assert.InDelta(t, tt.length, fill.Length(), epsilon)
+			length, height := fill.sheet()
+			assert.Empty(t, ValidatePlacement(placedShapes(parts, 1), length, height))
+		})
+	}
+}
+
+func TestNoFitPolygonFill_Compact(t *testing.T) {
+	square := NewPolygon(NewRectangle(0, 0, 2, 2))
+	parts := []*Part{
+		{Shape: square, Orientations: []orientation{{shape: square}}},
+		{Shape: square, Orientations: []orientation{{shape: square}}},
+		{Shape: square, Orientations: []orientation{{shape: square}}},
+	}
+
+	fill := NewNoFitPolygonFill(2, 100, nil)
+	fill.Run(parts)
+	assert.Equal(t, 0, fill.Compact(false))
+
+	// the first part leaves the gap as if it was never placed
+	fill.placed = fill.placed[1:]
+
+	assert.Equal(t, 2, fill.Compact(true))
+	assert.Equal(t, NewPoint(0, 0), parts[1].Offset.Point(1))
+	assert.Equal(t, NewPoint(2, 0), parts[2].Offset.Point(1))
+	assert.InDelta(t, 4, fill.Length(), epsilon)
+}
+
+func TestNoFitPolygonFill_Lock(t *testing.T) {
+	square := func() *Part {
+		shape := NewPolygon(NewRectangle(0, 0, 2, 2))
+		return &Part{Shape: shape, Orientations: []orientation{{shape: shape}}}
+	}
+
+	fill := NewNoFitPolygonFill(2, 100, nil)
+	locked := square()
+	assert.NoError(t, fill.Lock(locked, NewPoint(1, 0)))
+	assert.Error(t, fill.Lock(square(), NewPoint(2, 0)))
+	assert.Error(t, fill.Lock(square(), NewPoint(4, 1)))
+
+	parts := []*Part{square(), square()}
+	fill.Run(parts)
+	assert.Equal(t, NewPoint(3, 0), parts[0].Offset.Point(1))
+	assert.Equal(t, NewPoint(5, 0), parts[1].Offset.Point(1))
+
+	assert.Equal(t, 0, fill.Compact(true))
+	assert.Equal(t, NewPoint(1, 0), locked.Offset.Point(1))
+}
+
+func TestNoFitPolygonFill_Add(t *testing.T) {
+	square := func() *Part {
+		shape := NewPolygon(NewRectangle(0, 0, 2, 2))
+		return &Part{Shape: shape, Orientations: []orientation{{shape: shape}}}
+	}
+
+	fill := NewNoFitPolygonFill(2, 4, nil)
+	parts := []*Part{square(), square(), square()}
+	assert.True(t, fill.Add(parts[0]))
+	assert.True(t, fill.Add(parts[1]))
+	assert.False(t, fill.Add(parts[2]))
+	assert.Len(t, fill.placed, 2)
+	assert.InDelta(t, 4, fill.Length(), epsilon)
+}
@@ -55,6 +55,8 @@ type GeneticAlgorithm struct {
 	best       Individual
 	history    map[string]Individual
 	population []Individual
+	// the best fitness after each generation
+	trajectory []float32
 }
 
 type fitnessFunc func(Individual) float32
@@ -100,6 +102,33 @@ func (g *GeneticAlgorithm) Best() Individual {
 	return g.best
 }
 
+// Trajectory returns the best fitness after each generation run
+func (g *GeneticAlgorithm) Trajectory() []float32 {
+	return g.trajectory
+}
+
+// BestN returns up to n best distinct individuals evaluated during the run
+func (g *GeneticAlgorithm) BestN(n int) []Individual {
+	evaluated := make(map[string]Individual, len(g.history)+1)
+	for hash, individual := range g.history {
+		evaluated[hash] = individual
+	}
+	evaluated[g.best.Hash()] = g.best
+
+	individuals := make([]Individual, 0, len(evaluated))
+	for _, individual := range evaluated {
+		individuals = append(individuals, individual)
+	}
+	sort.Slice(individuals, func(i, j int) bool {
+		if individuals[i].fitness != individuals[j].fitness {
+			return individuals[i].fitness > individuals[j].fitness
+		}
+		return individuals[i].Hash() < individuals[j].Hash()
+	})
+
+	return individuals[:min(n, len(individuals))]
+}
+
 func (g *GeneticAlgorithm) Run(numGenerations int) {
 
 	g.population = g.newPopulation(g.populationSize)
@@ -126,6 +155,7 @@ func (g *GeneticAlgorithm) Run(numGenerations int) {
 		} else {
 			noImprovement++
 		}
+		g.trajectory = append(g.trajectory, g.best.fitness)
 
 		if noImprovement > noImprovementLimit {
 			fmt.Printf("No improvement for %d generations. Exiting.\n", noImprovementLimit)
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// the version of the cached orientations, it changes with the discretization
+const orientationCacheVersion = 1
+
+// OrientationCache shares the orientations of the identical parts in memory and
+// persists them to the directory, so the next runs do not rotate and discretize them again
+type OrientationCache struct {
+	// the directory of the cached orientations, empty if they are kept only in memory
+	dir string
+
+	mu           sync.Mutex
+	orientations map[string]orientation
+	// the orientations being computed, closed when they are ready
+	pending      map[string]chan struct{}
+	hits, misses int
+}
+
+// cachedOrientation is the persisted form of the orientation
+type cachedOrientation struct {
+	Outer     Ring
+	Inner     []Ring
+	Angle     float64
+	Occupancy OccupancyTable
+}
+
+func NewOrientationCache(dir string) *OrientationCache {
+	return &OrientationCache{
+		dir:          dir,
+		orientations: make(map[string]orientation),
+		pending:      make(map[string]chan struct{}),
+	}
+}
+
+// orientationKey returns the key of the orientation of the polygon rotated by the angle
+// and discretized into the strips of the direction with the step
+func orientationKey(poly Polygon, angle float64, rotate bool, step float64, direction string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprint(
+		orientationCacheVersion, poly.outerRing, poly.innerRings, angle, rotate, step, direction,
+	)))
+	return hex.EncodeToString(hash[:])
+}
+
+// Get returns the orientation of the key, it is computed only if it is neither in memory nor on disk.
+// The concurrent calls with the same key wait for the first one.
+func (c *OrientationCache) Get(key string, compute func() orientation) orientation {
+	c.mu.Lock()
+	if o, exists := c.orientations[key]; exists {
+		c.hits++
+		c.mu.Unlock()
+		return o
+	}
+	if ready, exists := c.pending[key]; exists {
+		c.hits++
+		c.mu.Unlock()
+		<-ready
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.orientations[key]
+	}
+	ready := make(chan struct{})
+	c.pending[key] = ready
+	c.mu.Unlock()
+
+	o, exists := c.load(key)
+	if !exists {
+		o = compute()
+		if err := c.store(key, o); err != nil {
+			log.Printf("Failed to cache the orientation: %v", err)
+		}
+	}
+
+	c.mu.Lock()
+	if exists {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.orientations[key] = o
+	delete(c.pending, key)
+	c.mu.Unlock()
+	close(ready)
+	return o
+}
+
+// Stats returns the number of the orientations taken from the cache and computed
+func (c *OrientationCache) Stats() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *OrientationCache) path(key string) string {
+	return filepath.Join(c.dir, key+".gob")
+}
+
+func (c *OrientationCache) load(key string) (orientation, bool) {
+	if c.dir == "" {
+		return orientation{}, false
+	}
+
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		return orientation{}, false
+	}
+	defer f.Close()
+
+	var cached cachedOrientation
+	if err := gob.NewDecoder(f).Decode(&cached); err != nil {
+		// the broken file is computed and written again
+		return orientation{}, false
+	}
+
+	return orientation{
+		shape:     NewPolygon(cached.Outer, cached.Inner...),
+		angle:     cached.Angle,
+		occupancy: cached.Occupancy,
+	}, true
+}
+
+// store writes the orientation to a temporary file and renames it,
+// so the concurrent runs never read a partially written file
+func (c *OrientationCache) store(key string, o orientation) error {
+	if c.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = gob.NewEncoder(f).Encode(cachedOrientation{
+		Outer:     o.shape.outerRing,
+		Inner:     o.shape.innerRings,
+		Angle:     o.angle,
+		Occupancy: o.occupancy,
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path(key))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrientationCache(t *testing.T) {
+	dir := t.TempDir()
+	poly := NewPolygon(NewRectangle(0, 0, 2, 4), NewRectangle(1, 1, 1, 1))
+	key := orientationKey(poly, 90, true, 0.5, stripsVertical)
+
+	computed := 0
+	compute := func() orientation {
+		computed++
+		rotated := poly.Rotate(90)
+		return orientation{shape: rotated, angle: 90, occupancy: Discretize(rotated, 0.5)}
+	}
+
+	cache := NewOrientationCache(dir)
+	first := cache.Get(key, compute)
+	// the identical part shares the orientation
+	assert.Equal(t, first, cache.Get(key, compute))
+	assert.Equal(t, 1, computed)
+
+	// the next run reads the orientation from the disk
+	got := NewOrientationCache(dir).Get(key, compute)
+	assert.Equal(t, 1, computed)
+	assert.Equal(t, first, got)
+
+	// the other resolution is another orientation
+	NewOrientationCache(dir).Get(orientationKey(poly, 90, true, 1, stripsVertical), compute)
+	assert.Equal(t, 2, computed)
+
+	hits, misses := cache.Stats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, misses)
+}
+
+func TestOrientationCache_MemoryOnly(t *testing.T) {
+	cache := NewOrientationCache("")
+	key := orientationKey(NewPolygon(NewRectangle(0, 0, 1, 1)), 0, true, 1, stripsVertical)
+
+	computed := 0
+	compute := func() orientation {
+		computed++
+		return orientation{}
+	}
+	cache.Get(key, compute)
+	NewOrientationCache("").Get(key, compute)
+	assert.Equal(t, 2, computed)
+}
+
+func TestCreateOrientations(t *testing.T) {
+	setGlobal(t, strips, stripsVertical)
+	setGlobal(t, &orientationCache, orientationCache)
+
+	figs := []Polygon{
+		NewPolygon(NewRectangle(0, 0, 2, 4)),
+		NewPolygon(Ring{{0, 0}, {1, 3}, {4, 0}, {0, 0}}),
+		NewPolygon(NewRectangle(0, 0, 2, 4)),
+	}
+	angles := rangeSlice(0, 360, 15)
+
+	orientationCache = NewOrientationCache("")
+	serial := createOrientations(figs, 0.5, 1, angles...)
+
+	orientationCache = NewOrientationCache("")
+	parallel := createOrientations(figs, 0.5, 8, angles...)
+
+	assert.Equal(t, serial, parallel)
+	assert.Len(t, parallel[0], len(angles))
+	// the identical figures share the orientations
+	assert.Equal(t, parallel[0], parallel[2])
+	_, misses := orientationCache.Stats()
+	assert.Equal(t, 2*len(angles), misses)
+}
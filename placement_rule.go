@@ -0,0 +1,110 @@
+package main
+
+const (
+	ruleBottomLeft    = "bottom-left"
+	ruleGravityCenter = "gravity-center"
+	ruleMaxContact    = "max-contact"
+	ruleMinEnvelope   = "min-envelope"
+)
+
+// placementRules are the placement rules selected by the name
+var placementRules = map[string]PlacementRule{
+	ruleBottomLeft:    BottomLeftRule{},
+	ruleGravityCenter: GravityCenterRule{},
+	ruleMaxContact:    MaxContactRule{},
+	ruleMinEnvelope:   MinEnvelopeRule{},
+}
+
+// PlacementRule ranks the bottom-left positions of the orientations of the part,
+// the positions with the equal scores are ranked from left to right and then from bottom to top
+type PlacementRule interface {
+	// Score returns the score of the orientation placed at the offset in the strips, the lower the better.
+	// It is called before the orientation is inserted into the vacancy table.
+	Score(fill *BottomLeftFill, o *orientation, offset Offset) float64
+}
+
+// BottomLeftRule places the part at the leftmost and then the lowest position
+type BottomLeftRule struct{}
+
+func (BottomLeftRule) Score(*BottomLeftFill, *orientation, Offset) float64 {
+	return 0
+}
+
+// GravityCenterRule places the part with the lowest center of gravity along the length of the sheet
+type GravityCenterRule struct{}
+
+func (GravityCenterRule) Score(fill *BottomLeftFill, o *orientation, offset Offset) float64 {
+	minx, miny, _, _ := o.shape.Bounds()
+	centroid := o.shape.Centroid()
+	if fill.horizontal {
+		return float64(offset.column)*fill.step + centroid.Y - miny
+	}
+	return float64(offset.column)*fill.step + centroid.X - minx
+}
+
+// MaxContactRule places the part touching the placed parts and the sheet edges
+// along the longest perimeter. The contact is measured on the strips of the part.
+type MaxContactRule struct{}
+
+func (MaxContactRule) Score(fill *BottomLeftFill, o *orientation, offset Offset) float64 {
+	var contact float64
+	for stripNum, strip := range o.occupancy {
+		column := offset.column + stripNum
+		for _, rng := range strip {
+			rng = rng.Add(offset.y)
+
+			// the vacant range ends where the placed parts or the sheet edges begin
+			for _, vacant := range fill.getVacancyStrip(column) {
+				if !vacant.Includes(rng) {
+					continue
+				}
+				if vacant.Start >= rng.Start-epsilon {
+					contact += fill.step
+				}
+				if vacant.End <= rng.End+epsilon {
+					contact += fill.step
+				}
+				break
+			}
+
+			if column == 0 {
+				contact += rng.Length()
+			} else {
+				contact += rng.Length() - vacantLength(fill.getVacancyStrip(column-1), rng)
+			}
+			contact += rng.Length() - vacantLength(fill.getVacancyStrip(column+1), rng)
+		}
+	}
+	return -contact
+}
+
+// vacantLength returns the length of the range lying in the vacant ranges of the strip
+func vacantLength(strip Strip, rng Range) float64 {
+	var length float64
+	for _, vacant := range strip {
+		length += max(0, min(vacant.End, rng.End)-max(vacant.Start, rng.Start))
+	}
+	return length
+}
+
+// MinEnvelopeRule places the part so that the bounding rectangle
+// of the placed parts has the minimal area
+type MinEnvelopeRule struct{}
+
+func (MinEnvelopeRule) Score(fill *BottomLeftFill, o *orientation, offset Offset) float64 {
+	columns, height := envelope(o.occupancy, offset)
+	for i, part := range fill.placed {
+		c, h := envelope(part.bestOrienation().occupancy, fill.offsets[i])
+		columns, height = max(columns, c), max(height, h)
+	}
+	return float64(columns) * fill.step * height
+}
+
+// envelope returns the number of the columns and the height the part at the offset spans from the origin
+func envelope(occupancy OccupancyTable, offset Offset) (int, float64) {
+	var height float64
+	for _, strip := range occupancy {
+		height = max(height, offset.y+strip.End())
+	}
+	return offset.column + len(occupancy), height
+}
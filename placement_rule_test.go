@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rectangleOrientation(height, width int) orientation {
+	return orientation{
+		shape:     NewPolygon(NewRectangle(0, 0, float64(height), float64(width))),
+		occupancy: NewRectanlePart(height, width),
+	}
+}
+
+func TestPlacementRule_Score(t *testing.T) {
+	tests := []struct {
+		name        string
+		height      float32
+		rule        PlacementRule
+		placed      []orientation
+		orientation orientation
+		offset      Offset
+		expected    float64
+	}{
+		{
+			name:        "bottom-left",
+			height:      2,
+			rule:        BottomLeftRule{},
+			orientation: rectangleOrientation(2, 2),
+			offset:      Offset{column: 3},
+			expected:    0,
+		},
+		{
+			name:        "gravity center",
+			height:      2,
+			rule:        GravityCenterRule{},
+			orientation: rectangleOrientation(2, 2),
+			offset:      Offset{column: 3},
+			expected:    4,
+		},
+		{
+			name:        "contact with the sheet edges",
+			height:      2,
+			rule:        MaxContactRule{},
+			orientation: rectangleOrientation(2, 1),
+			expected:    -4,
+		},
+		{
+			name:        "contact with the placed part",
+			height:      2,
+			rule:        MaxContactRule{},
+			placed:      []orientation{rectangleOrientation(2, 1)},
+			orientation: rectangleOrientation(1, 1),
+			offset:      Offset{column: 1},
+			expected:    -2,
+		},
+		{
+			name:        "envelope",
+			height:      4,
+			rule:        MinEnvelopeRule{},
+			placed:      []orientation{rectangleOrientation(1, 2)},
+			orientation: rectangleOrientation(3, 1),
+			offset:      Offset{column: 2},
+			expected:    9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill := NewBottomLeftFill(tt.height, 10)
+			parts := make([]*Part, len(tt.placed))
+			for i, o := range tt.placed {
+				parts[i] = &Part{Orientations: []orientation{o}}
+			}
+			fill.Run(parts)
+
+			assert.InDelta(t, tt.expected, tt.rule.Score(fill, &tt.orientation, tt.offset), epsilon)
+		})
+	}
+}
+
+func TestBottomLeftFill_PlacementRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        PlacementRule
+		orientation int
+	}{
+		{
+			name:        "bottom-left keeps the first orientation",
+			rule:        BottomLeftRule{},
+			orientation: 0,
+		},
+		{
+			name:        "gravity center prefers the narrow orientation",
+			rule:        GravityCenterRule{},
+			orientation: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill := NewBottomLeftFill(2, 10, WithPlacementRule(tt.rule))
+			part := &Part{Orientations: []orientation{rectangleOrientation(1, 3), rectangleOrientation(2, 1)}}
+			fill.Run([]*Part{{Orientations: []orientation{rectangleOrientation(2, 1)}}, part})
+
+			assert.Equal(t, tt.orientation, part.BestOrientationNum)
+			assert.Equal(t, Offset{column: 1}, part.Offset)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+)
+
+// Remnant is the reusable offcut of the sheet
+type Remnant struct {
+	// the clockwise outline of the offcut starting at the origin
+	Outline Ring `json:"outline"`
+	// only the parts of the same material are nested onto the remnant
+	Material string `json:"material,omitempty"`
+}
+
+func (r Remnant) Area() float64 {
+	return r.Outline.Area()
+}
+
+// RemnantInventory is the local stock of the remnants kept between the runs
+type RemnantInventory struct {
+	Remnants []Remnant `json:"remnants"`
+}
+
+// LoadRemnantInventory reads the inventory from the file, the missing file is an empty inventory.
+// The outlines are moved to the origin and made clockwise.
+func LoadRemnantInventory(path string) (*RemnantInventory, error) {
+	var inventory RemnantInventory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &inventory, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remnant inventory: %w", err)
+	}
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("failed to parse remnant inventory: %w", err)
+	}
+
+	for i, remnant := range inventory.Remnants {
+		if len(remnant.Outline) < 3 {
+			return nil, fmt.Errorf("remnant %d has %d points, at least 3 are required", i, len(remnant.Outline))
+		}
+		outline := slices.Clone(remnant.Outline)
+		if outline[0] != outline[len(outline)-1] {
+			outline = closeRing(outline)
+		}
+		if outline.Area() < 0 {
+			slices.Reverse(outline)
+		}
+		minx, miny, _, _ := NewPolygon(outline).Bounds()
+		inventory.Remnants[i].Outline = NewPolygon(outline).Offset(NewPoint(-minx, -miny)).outerRing
+	}
+	return &inventory, nil
+}
+
+func (inv *RemnantInventory) Save(path string) error {
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Remove removes the remnants with the given numbers
+func (inv *RemnantInventory) Remove(nums ...int) {
+	var kept []Remnant
+	for i, remnant := range inv.Remnants {
+		if !slices.Contains(nums, i) {
+			kept = append(kept, remnant)
+		}
+	}
+	inv.Remnants = kept
+}
+
+// stripRect is the rectangle of the vacant ranges spanning the consecutive strips
+type stripRect struct {
+	column  int
+	columns int
+	rng     Range
+}
+
+func (r stripRect) area(step float64) float64 {
+	return float64(r.columns) * step * r.rng.Length()
+}
+
+func (r stripRect) overlaps(other stripRect) bool {
+	return r.column < other.column+other.columns && other.column < r.column+r.columns &&
+		r.rng.Start < other.rng.End-epsilon && other.rng.Start < r.rng.End-epsilon
+}
+
+// remnant returns the remnant of the rectangle, the columns go along y for the horizontal strips
+func (r stripRect) remnant(step float64, horizontal bool) Remnant {
+	length := float64(r.columns) * step
+	if horizontal {
+		return Remnant{Outline: NewRectangle(0, 0, length, r.rng.Length())}
+	}
+	return Remnant{Outline: NewRectangle(0, 0, r.rng.Length(), length)}
+}
+
+// FindRemnants returns the non-overlapping vacant rectangles not smaller than the min size
+// in both dimensions, the largest ones go first. For each range of each strip the largest rectangle
+// starting at it is found by extending it through the following strips.
+func FindRemnants(vacancy OccupancyTable, step, minSize float64) []stripRect {
+	var candidates []stripRect
+	for column, strip := range vacancy {
+		for _, rng := range strip {
+			var best stripRect
+			for k, cur := column, rng; k < len(vacancy); k++ {
+				if k > column {
+					cur = widestIntersection(vacancy[k], cur)
+				}
+				if cur.Length() < minSize-epsilon {
+					break
+				}
+
+				rect := stripRect{column: column, columns: k - column + 1, rng: cur}
+				if float64(rect.columns)*step >= minSize-epsilon && rect.area(step) > best.area(step) {
+					best = rect
+				}
+			}
+			if best.columns > 0 {
+				candidates = append(candidates, best)
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].area(step) > candidates[j].area(step)
+	})
+
+	var remnants []stripRect
+	for _, candidate := range candidates {
+		overlapping := slices.ContainsFunc(remnants, candidate.overlaps)
+		if !overlapping {
+			remnants = append(remnants, candidate)
+		}
+	}
+	return remnants
+}
+
+// widestIntersection returns the widest intersection of the range with the ranges of the strip
+func widestIntersection(strip Strip, rng Range) Range {
+	var widest Range
+	for _, r := range strip {
+		intersection := Range{Start: max(r.Start, rng.Start), End: min(r.End, rng.End)}
+		if intersection.Length() > widest.Length() {
+			widest = intersection
+		}
+	}
+	return widest
+}
+
+// remnantLayout is the placement of the parts onto the remnant of the inventory
+type remnantLayout struct {
+	// the number of the remnant in the inventory
+	remnant int
+	parts   []*Part
+	// the numbers of the parts in the dataset
+	nums []int
+}
+
+// nestRemnants places the parts with the numbers onto the remnants in the order of the inventory,
+// the largest parts go first. It returns the layouts of the used remnants
+// and the numbers of the parts left for the new sheet.
+func nestRemnants(parts []*Part, nums []int, remnants []Remnant, newFill func(Remnant) *BottomLeftFill) ([]remnantLayout, []int) {
+	remaining := slices.Clone(nums)
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return parts[remaining[i]].Shape.Area() > parts[remaining[j]].Shape.Area()
+	})
+
+	var layouts []remnantLayout
+	for i, remnant := range remnants {
+		fill := newFill(remnant)
+		layout := remnantLayout{remnant: i}
+
+		var left []int
+		for _, num := range remaining {
+			if fill.Add(parts[num]) {
+				layout.parts = append(layout.parts, parts[num])
+				layout.nums = append(layout.nums, num)
+			} else {
+				left = append(left, num)
+			}
+		}
+
+		remaining = left
+		if len(layout.parts) > 0 {
+			layouts = append(layouts, layout)
+		}
+	}
+
+	sort.Ints(remaining)
+	return layouts, remaining
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func squarePart(size int) *Part {
+	square := NewPolygon(NewRectangle(0, 0, float64(size), float64(size)))
+	return &Part{
+		Shape:        square,
+		Orientations: []orientation{{shape: square, occupancy: NewRectanlePart(size, size)}},
+	}
+}
+
+func TestFindRemnants(t *testing.T) {
+	vacancy := OccupancyTable{
+		{{Start: 0, End: 4}},
+		{{Start: 0, End: 4}},
+		{{Start: 2, End: 4}},
+		{{Start: 2, End: 4}},
+		{{Start: 0, End: 1}},
+	}
+
+	expected := []stripRect{
+		{column: 0, columns: 2, rng: Range{Start: 0, End: 4}},
+		{column: 2, columns: 2, rng: Range{Start: 2, End: 4}},
+	}
+	assert.Equal(t, expected, FindRemnants(vacancy, 1, 2))
+	assert.Equal(t, Remnant{Outline: NewRectangle(0, 0, 4, 2)}, expected[0].remnant(1, false))
+	assert.Equal(t, Remnant{Outline: NewRectangle(0, 0, 2, 4)}, expected[0].remnant(1, true))
+}
+
+func TestBottomLeftFill_SheetOutline(t *testing.T) {
+	outline := Ring{{0, 0}, {0, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 0}, {0, 0}}
+	fill := NewBottomLeftFill(4, 4, WithSheetOutline(outline))
+
+	parts := []*Part{squarePart(2), squarePart(2), squarePart(2), squarePart(2)}
+	for _, part := range parts[:3] {
+		assert.True(t, fill.Add(part))
+	}
+	assert.False(t, fill.Add(parts[3]))
+
+	assert.Equal(t, Offset{column: 0, y: 0}, parts[0].Offset)
+	assert.Equal(t, Offset{column: 0, y: 2}, parts[1].Offset)
+	assert.Equal(t, Offset{column: 2, y: 0}, parts[2].Offset)
+}
+
+func TestNestRemnants(t *testing.T) {
+	remnants := []Remnant{
+		{Outline: NewRectangle(0, 0, 2, 2)},
+		{Outline: NewRectangle(0, 0, 4, 4)},
+	}
+	parts := []*Part{squarePart(1), squarePart(5), squarePart(3), squarePart(2)}
+
+	layouts, remaining := nestRemnants(parts, []int{0, 1, 2, 3}, remnants, func(remnant Remnant) *BottomLeftFill {
+		_, _, maxx, maxy := NewPolygon(remnant.Outline).Bounds()
+		return NewBottomLeftFill(float32(maxy), int(maxx), WithSheetOutline(remnant.Outline))
+	})
+
+	assert.Equal(t, []remnantLayout{
+		{remnant: 0, parts: []*Part{parts[3]}, nums: []int{3}},
+		{remnant: 1, parts: []*Part{parts[2], parts[0]}, nums: []int{2, 0}},
+	}, layouts)
+	assert.Equal(t, []int{1}, remaining)
+	assert.Empty(t, ValidateWithin(placedShapes(layouts[1].parts, 1), remnants[1].Outline))
+}
+
+func TestRemnantInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remnants.json")
+
+	inventory, err := LoadRemnantInventory(path)
+	assert.NoError(t, err)
+	assert.Empty(t, inventory.Remnants)
+
+	// the counter-clockwise outline away from the origin
+	err = os.WriteFile(path, []byte(`{"remnants": [
+		{"outline": [{"X": 1, "Y": 1}, {"X": 3, "Y": 1}, {"X": 3, "Y": 2}, {"X": 1, "Y": 2}]},
+		{"outline": [{"X": 0, "Y": 0}, {"X": 0, "Y": 5}, {"X": 5, "Y": 5}, {"X": 5, "Y": 0}]}
+	]}`), 0o644)
+	assert.NoError(t, err)
+
+	inventory, err = LoadRemnantInventory(path)
+	assert.NoError(t, err)
+	assert.Len(t, inventory.Remnants, 2)
+	assert.Equal(t, Ring{{0, 0}, {0, 1}, {2, 1}, {2, 0}, {0, 0}}, inventory.Remnants[0].Outline)
+	assert.InDelta(t, 2, inventory.Remnants[0].Area(), epsilon)
+
+	inventory.Remove(0)
+	assert.NoError(t, inventory.Save(path))
+
+	saved, err := LoadRemnantInventory(path)
+	assert.NoError(t, err)
+	assert.Equal(t, inventory, saved)
+}
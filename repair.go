@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Diagnostic describes a problem found in the geometry of a polygon
+type Diagnostic struct {
+	// the ring with the problem: 0 is the outer ring, i is the inner ring i-1
+	Ring    int
+	Message string
+	// false if the problem cannot be repaired automatically
+	Repaired bool
+}
+
+func (d Diagnostic) String() string {
+	status := "repaired"
+	if !d.Repaired {
+		status = "not repaired"
+	}
+	return fmt.Sprintf("ring %d: %s (%s)", d.Ring, d.Message, status)
+}
+
+// FormatDiagnostics joins the diagnostics into a single line
+func FormatDiagnostics(diagnostics []Diagnostic) string {
+	messages := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		messages[i] = d.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// RepairPolygon closes the rings, removes the repeated and collinear points,
+// orients all rings clockwise and removes the holes lying outside the outer ring.
+// Self-intersections and crossing rings cannot be repaired and are only reported.
+// It returns an error if the outer ring is degenerate, so there is nothing to nest.
+func RepairPolygon(poly Polygon) (Polygon, []Diagnostic, error) {
+	var diagnostics []Diagnostic
+
+	outer, diags := repairRing(poly.outerRing, 0)
+	diagnostics = append(diagnostics, diags...)
+	if len(outer) == 0 {
+		return Polygon{}, diagnostics, errors.New("outer ring is degenerate")
+	}
+
+	var inners []Ring
+	// the numbers of the kept rings in the polygon
+	nums := []int{0}
+	for i, innerRing := range poly.innerRings {
+		inner, diags := repairRing(innerRing, i+1)
+		diagnostics = append(diagnostics, diags...)
+		if len(inner) == 0 {
+			continue
+		}
+
+		if !ringsCross(outer, inner) && !outer.Contains(inner[0]) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Ring: i + 1, Message: "hole lies outside the outer ring", Repaired: true,
+			})
+			continue
+		}
+		inners = append(inners, inner)
+		nums = append(nums, i+1)
+	}
+
+	rings := append([]Ring{outer}, inners...)
+	for i, ring := range rings {
+		if selfIntersects(ring) {
+			diagnostics = append(diagnostics, Diagnostic{Ring: nums[i], Message: "ring intersects itself"})
+		}
+		for j := i + 1; j < len(rings); j++ {
+			if ringsCross(ring, rings[j]) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Ring: nums[i], Message: fmt.Sprintf("ring intersects ring %d", nums[j]),
+				})
+			}
+		}
+	}
+
+	return NewPolygon(outer, inners...), diagnostics, nil
+}
+
+// repairRing returns the closed clockwise ring without repeated and collinear points
+func repairRing(ring Ring, num int) (Ring, []Diagnostic) {
+	var diagnostics []Diagnostic
+
+	points := slices.Clone(ring)
+	if !points.IsClosed() && len(points) > 0 {
+		diagnostics = append(diagnostics, Diagnostic{Ring: num, Message: "ring is not closed", Repaired: true})
+	} else if len(points) > 0 {
+		points = points[:len(points)-1]
+	}
+
+	if compacted := slices.Compact(points); len(compacted) != len(points) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Ring: num, Message: fmt.Sprintf("%d repeated points removed", len(points)-len(compacted)), Repaired: true,
+		})
+		points = compacted
+	}
+	// the last point may repeat the first one
+	for len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+
+	if removed := removeCollinear(&points); removed > 0 {
+		diagnostics = append(diagnostics, Diagnostic{
+			Ring: num, Message: fmt.Sprintf("%d collinear points removed", removed), Repaired: true,
+		})
+	}
+
+	if len(points) < 3 {
+		diagnostics = append(diagnostics, Diagnostic{Ring: num, Message: "ring is degenerate"})
+		return nil, diagnostics
+	}
+
+	repaired := append(points, points[0])
+	if repaired.Area() < 0 {
+		slices.Reverse(repaired)
+		diagnostics = append(diagnostics, Diagnostic{Ring: num, Message: "ring is counter-clockwise", Repaired: true})
+	}
+
+	return repaired, diagnostics
+}
+
+// removeCollinear removes the points lying on the line between their neighbours
+// from the open ring and returns the number of removed points
+func removeCollinear(points *Ring) int {
+	removed := 0
+	for i := 0; len(*points) >= 3 && i < len(*points); {
+		n := len(*points)
+		prev, next := (*points)[(i+n-1)%n], (*points)[(i+1)%n]
+		if turn(prev, (*points)[i], next) == 0 {
+			*points = slices.Delete(*points, i, i+1)
+			removed++
+			// the previous point may become collinear
+			i = max(0, i-1)
+			continue
+		}
+		i++
+	}
+	return removed
+}
+
+// selfIntersects returns true if non-adjacent edges of the closed ring cross each other
+func selfIntersects(ring Ring) bool {
+	n := len(ring) - 1
+	for i := 0; i < n; i++ {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if (Line{Start: ring[i], End: ring[i+1]}).Crosses(Line{Start: ring[j], End: ring[j+1]}) {
+				return true
+			}
+		}
+	}
+	return false
+}
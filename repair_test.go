@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairPolygon(t *testing.T) {
+	tests := []struct {
+		name        string
+		poly        Polygon
+		expected    Polygon
+		diagnostics []Diagnostic
+		err         string
+	}{
+		{
+			name:     "valid square",
+			poly:     NewPolygon(NewRectangle(0, 0, 2, 2)),
+			expected: NewPolygon(NewRectangle(0, 0, 2, 2)),
+		},
+		{
+			name: "open ring with repeated points",
+			poly: NewPolygon(Ring{{0, 0}, {0, 2}, {0, 2}, {2, 2}, {2, 2}, {2, 0}}),
+			expected: NewPolygon(
+				Ring{{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0}},
+			),
+			diagnostics: []Diagnostic{
+				{Ring: 0, Message: "ring is not closed", Repaired: true},
+				{Ring: 0, Message: "2 repeated points removed", Repaired: true},
+			},
+		},
+		{
+			name:     "counter-clockwise ring with collinear point",
+			poly:     NewPolygon(Ring{{0, 0}, {1, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}),
+			expected: NewPolygon(Ring{{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0}}),
+			diagnostics: []Diagnostic{
+				{Ring: 0, Message: "1 collinear points removed", Repaired: true},
+				{Ring: 0, Message: "ring is counter-clockwise", Repaired: true},
+			},
+		},
+		{
+			name:     "hole outside the outer ring",
+			poly:     NewPolygon(NewRectangle(0, 0, 2, 2), NewRectangle(5, 5, 1, 1)),
+			expected: NewPolygon(NewRectangle(0, 0, 2, 2)),
+			diagnostics: []Diagnostic{
+				{Ring: 1, Message: "hole lies outside the outer ring", Repaired: true},
+			},
+		},
+		{
+			name:     "self-intersection",
+			poly:     NewPolygon(Ring{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}),
+			expected: NewPolygon(Ring{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}),
+			diagnostics: []Diagnostic{
+				{Ring: 0, Message: "ring intersects itself"},
+			},
+		},
+		{
+			name:     "hole crosses the outer ring",
+			poly:     NewPolygon(NewRectangle(0, 0, 2, 2), NewRectangle(1, 1, 2, 2)),
+			expected: NewPolygon(NewRectangle(0, 0, 2, 2), NewRectangle(1, 1, 2, 2)),
+			diagnostics: []Diagnostic{
+				{Ring: 0, Message: "ring intersects ring 1"},
+			},
+		},
+		{
+			name:     "numbers of the rings after the dropped hole",
+			poly:     NewPolygon(NewRectangle(0, 0, 4, 4), NewRectangle(10, 10, 1, 1), NewRectangle(3, 3, 2, 2)),
+			expected: NewPolygon(NewRectangle(0, 0, 4, 4), NewRectangle(3, 3, 2, 2)),
+			diagnostics: []Diagnostic{
+				{Ring: 1, Message: "hole lies outside the outer ring", Repaired: true},
+				{Ring: 0, Message: "ring intersects ring 2"},
+			},
+		},
+		{
+			name: "degenerate outer ring",
+			poly: NewPolygon(Ring{{0, 0}, {1, 1}, {2, 2}, {0, 0}}, NewRectangle(0, 0, 1, 1)),
+			diagnostics: []Diagnostic{
+				{Ring: 0, Message: "1 collinear points removed", Repaired: true},
+				{Ring: 0, Message: "ring is degenerate"},
+			},
+			err: "outer ring is degenerate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diagnostics, err := RepairPolygon(tt.poly)
+			assert.Equal(t, tt.diagnostics, diagnostics)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Repeat restricts the positions of the part to the repeat of the fabric pattern,
+// so the stripes or the checks of the parts match. The position is the offset
+// of the rotated part, the zero interval leaves the axis free.
+// Along the strips the parts move by the resolution, so the tolerance must be at least
+// half of it unless the interval is a multiple of it and the offset is on the strips.
+type Repeat struct {
+	// the intervals of the pattern along x and y
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	// the positions of the part modulo the intervals
+	OffsetX float64 `json:"offset_x"`
+	OffsetY float64 `json:"offset_y"`
+	// the allowed deviation of the position from the pattern
+	Tolerance float64 `json:"tolerance"`
+}
+
+// repeatAxis is the repeat of the pattern along one axis
+type repeatAxis struct {
+	interval  float64
+	offset    float64
+	tolerance float64
+}
+
+// next returns the nearest allowed position not less than the given one
+func (a repeatAxis) next(pos float64) float64 {
+	if a.interval <= 0 {
+		return pos
+	}
+
+	// the distance from the previous repeat of the pattern
+	rel := math.Mod(pos-a.offset, a.interval)
+	if rel < 0 {
+		rel += a.interval
+	}
+	if rel <= a.tolerance+epsilon || rel >= a.interval-a.tolerance-epsilon {
+		return pos
+	}
+	return pos + a.interval - a.tolerance - rel
+}
+
+// lines returns the positions of the pattern within the length
+func (a repeatAxis) lines(length float64) []float64 {
+	if a.interval <= 0 {
+		return nil
+	}
+
+	var lines []float64
+	start := a.offset - math.Floor(a.offset/a.interval)*a.interval
+	for pos := start; pos <= length+epsilon; pos += a.interval {
+		lines = append(lines, pos)
+	}
+	return lines
+}
+
+// reachable returns true if the columns at the multiples of the step reach
+// every repeat of the pattern within the tolerance
+func (a repeatAxis) reachable(step float64) bool {
+	if a.interval <= 0 || 2*a.tolerance >= step-epsilon {
+		return true
+	}
+	// the repeats keep their distance to the columns only if the interval is a multiple of the step
+	if ratio := a.interval / step; math.Abs(ratio-math.Round(ratio)) > epsilon {
+		return false
+	}
+	rel := math.Mod(a.offset, step)
+	if rel < 0 {
+		rel += step
+	}
+	return min(rel, step-rel) <= a.tolerance+epsilon
+}
+
+// checkStrips returns an error if the strips of the width step miss the repeats of the pattern
+// along the sheet, the sheet grows along y for the horizontal strips
+func (r *Repeat) checkStrips(step float64, horizontal bool) error {
+	along, across := r.axes()
+	if horizontal {
+		along = across
+	}
+	if !along.reachable(step) {
+		return fmt.Errorf("tolerance %g of the repeat must be at least half of the strip width %g "+
+			"unless the interval %g and the offset %g are on the strips", r.Tolerance, step, along.interval, along.offset)
+	}
+	return nil
+}
+
+// axes returns the repeats of the pattern along x and y
+func (r *Repeat) axes() (x, y repeatAxis) {
+	if r == nil {
+		return repeatAxis{}, repeatAxis{}
+	}
+	return repeatAxis{interval: r.X, offset: r.OffsetX, tolerance: r.Tolerance},
+		repeatAxis{interval: r.Y, offset: r.OffsetY, tolerance: r.Tolerance}
+}
+
+// repeats returns the distinct repeats of the parts
+func repeats(parts []*Part) []*Repeat {
+	var distinct []*Repeat
+	for _, part := range parts {
+		if part.Repeat != nil && !slices.ContainsFunc(distinct, func(r *Repeat) bool { return *r == *part.Repeat }) {
+			distinct = append(distinct, part.Repeat)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatAxis_Next(t *testing.T) {
+	tests := []struct {
+		name     string
+		axis     repeatAxis
+		pos      float64
+		expected float64
+	}{
+		{
+			name:     "free axis",
+			pos:      1.5,
+			expected: 1.5,
+		},
+		{
+			name:     "on the pattern",
+			axis:     repeatAxis{interval: 4, offset: 1},
+			pos:      5,
+			expected: 5,
+		},
+		{
+			name:     "between the repeats",
+			axis:     repeatAxis{interval: 4, offset: 1},
+			pos:      6,
+			expected: 9,
+		},
+		{
+			name:     "before the offset",
+			axis:     repeatAxis{interval: 4, offset: 1},
+			pos:      0,
+			expected: 1,
+		},
+		{
+			name:     "within the tolerance",
+			axis:     repeatAxis{interval: 4, offset: 1, tolerance: 0.5},
+			pos:      4.5,
+			expected: 4.5,
+		},
+		{
+			name:     "to the tolerance of the next repeat",
+			axis:     repeatAxis{interval: 4, offset: 1, tolerance: 0.5},
+			pos:      2,
+			expected: 4.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, tt.axis.next(tt.pos), epsilon)
+		})
+	}
+}
+
+func TestRepeatAxis_Lines(t *testing.T) {
+	assert.Equal(t, []float64{1, 5, 9}, repeatAxis{interval: 4, offset: 9}.lines(10))
+	assert.Nil(t, repeatAxis{}.lines(10))
+}
+
+func TestRepeats(t *testing.T) {
+	parts := []*Part{
+		{Repeat: &Repeat{X: 5}},
+		{},
+		{Repeat: &Repeat{X: 5}},
+		{Repeat: &Repeat{Y: 5}},
+	}
+	assert.Equal(t, []*Repeat{{X: 5}, {Y: 5}}, repeats(parts))
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Report summarizes the placement of the parts on the sheet
+type Report struct {
+	// the material of the parts, empty for the parts nested onto the sheet of the dataset
+	Material string `json:"material,omitempty"`
+	// the number of the remnant of the inventory the parts are nested onto, nil for the new sheet
+	Remnant     *int    `json:"remnant,omitempty"`
+	SheetWidth  float64 `json:"sheet_width"`
+	SheetHeight float64 `json:"sheet_height"`
+	SheetArea   float64 `json:"sheet_area"`
+	PartsArea   float64 `json:"parts_area"`
+	// the percentage of the used sheet covered by the parts
+	Utilization float64 `json:"utilization"`
+	// the bounding rectangle of the placed parts
+	Envelope Bounds `json:"envelope"`
+	// the area of the convex hull of the placed parts and
+	// the percentage of the hull covered by the parts
+	HullArea        float64        `json:"hull_area"`
+	HullUtilization float64        `json:"hull_utilization"`
+	Placed          int            `json:"placed"`
+	Unplaced        int            `json:"unplaced"`
+	Parts           []PartReport   `json:"parts"`
+	Fillers         []FillerReport `json:"fillers,omitempty"`
+	// the numbers of the mandatory parts which do not fit into the sheet
+	Leftovers         []int     `json:"leftovers,omitempty"`
+	RuntimeSeconds    float64   `json:"runtime_seconds"`
+	Generations       int       `json:"generations"`
+	FitnessTrajectory []float32 `json:"fitness_trajectory"`
+	// the reports of the material groups nested separately
+	Groups []*Report `json:"groups,omitempty"`
+}
+
+// PartReport describes the placed part
+type PartReport struct {
+	// the number of the part in the dataset
+	Index  int     `json:"index"`
+	Area   float64 `json:"area"`
+	Angle  float64 `json:"angle"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Bounds Bounds  `json:"bounds"`
+}
+
+// FillerReport is the number of the placed parts of the filler piece
+// along with the mandatory minimum and the quantity of the piece
+type FillerReport struct {
+	PieceID string `json:"piece_id"`
+	Min     int    `json:"min"`
+	Placed  int    `json:"placed"`
+	Total   int    `json:"total"`
+}
+
+type Bounds struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+}
+
+// NewReport returns the report of the ordered parts placed on the used sheet of the width and height.
+// The indices are the numbers of the ordered parts in the dataset.
+func NewReport(ordered []*Part, indices []int, step float64, width, height float64) *Report {
+	report := &Report{
+		SheetWidth:  width,
+		SheetHeight: height,
+		SheetArea:   width * height,
+		Placed:      len(ordered),
+		Envelope:    Bounds{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)},
+	}
+
+	var points []Point
+	for i, placed := range placedShapes(ordered, step) {
+		minx, miny, maxx, maxy := placed.Bounds()
+		offset := ordered[i].Offset.Point(step)
+		part := PartReport{
+			Index:  indices[i],
+			Area:   placed.Area(),
+			Angle:  ordered[i].bestOrienation().angle,
+			X:      offset.X,
+			Y:      offset.Y,
+			Bounds: Bounds{MinX: minx, MinY: miny, MaxX: maxx, MaxY: maxy},
+		}
+		report.Parts = append(report.Parts, part)
+		report.PartsArea += part.Area
+
+		report.Envelope = Bounds{
+			MinX: min(report.Envelope.MinX, minx),
+			MinY: min(report.Envelope.MinY, miny),
+			MaxX: max(report.Envelope.MaxX, maxx),
+			MaxY: max(report.Envelope.MaxY, maxy),
+		}
+		points = append(points, placed.outerRing...)
+	}
+
+	if len(ordered) == 0 {
+		report.Envelope = Bounds{}
+	}
+	if report.SheetArea > 0 {
+		report.Utilization = report.PartsArea / report.SheetArea * 100
+	}
+	if len(points) > 0 {
+		report.HullArea = convexHull(points).Area()
+	}
+	if report.HullArea > 0 {
+		report.HullUtilization = report.PartsArea / report.HullArea * 100
+	}
+
+	return report
+}
+
+// CombineReports returns the report of the groups nested onto the separate sheets.
+// The envelope and the hull are not combined, since the sheets do not share the coordinates.
+func CombineReports(groups []*Report) *Report {
+	report := &Report{Groups: groups}
+	for _, group := range groups {
+		report.SheetArea += group.SheetArea
+		report.PartsArea += group.PartsArea
+		report.Placed += group.Placed
+		report.Unplaced += group.Unplaced
+		report.Parts = append(report.Parts, group.Parts...)
+		report.Fillers = append(report.Fillers, group.Fillers...)
+		report.Leftovers = append(report.Leftovers, group.Leftovers...)
+		report.Generations = max(report.Generations, group.Generations)
+	}
+	sort.Ints(report.Leftovers)
+	if report.SheetArea > 0 {
+		report.Utilization = report.PartsArea / report.SheetArea * 100
+	}
+	return report
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in the human-readable form, the combined report
+// is followed by the reports of the groups
+func (r *Report) WriteText(w io.Writer) error {
+	if len(r.Groups) > 0 {
+		return r.writeCombinedText(w)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if r.Material != "" {
+		fmt.Fprintf(tw, "Material:\t%s\n", r.Material)
+	}
+	if r.Remnant != nil {
+		fmt.Fprintf(tw, "Remnant:\t%d\n", *r.Remnant)
+	}
+	fmt.Fprintf(tw, "Sheet:\t%.4f x %.4f\n", r.SheetWidth, r.SheetHeight)
+	fmt.Fprintf(tw, "Sheet area:\t%.4f\n", r.SheetArea)
+	fmt.Fprintf(tw, "Parts area:\t%.4f\n", r.PartsArea)
+	fmt.Fprintf(tw, "Utilization:\t%.2f%%\n", r.Utilization)
+	fmt.Fprintf(tw, "Envelope:\t(%.4f, %.4f) - (%.4f, %.4f)\n",
+		r.Envelope.MinX, r.Envelope.MinY, r.Envelope.MaxX, r.Envelope.MaxY)
+	fmt.Fprintf(tw, "Hull area:\t%.4f\n", r.HullArea)
+	fmt.Fprintf(tw, "Hull utilization:\t%.2f%%\n", r.HullUtilization)
+	fmt.Fprintf(tw, "Placed parts:\t%d\n", r.Placed)
+	fmt.Fprintf(tw, "Unplaced parts:\t%d\n", r.Unplaced)
+	for _, filler := range r.Fillers {
+		fmt.Fprintf(tw, "Filler %s:\t%d of %d, min %d\n", filler.PieceID, filler.Placed, filler.Total, filler.Min)
+	}
+	if len(r.Leftovers) > 0 {
+		fmt.Fprintf(tw, "Leftover parts:\t%v\n", r.Leftovers)
+	}
+	fmt.Fprintf(tw, "Runtime:\t%.2fs\n", r.RuntimeSeconds)
+	fmt.Fprintf(tw, "Generations:\t%d\n", r.Generations)
+	fmt.Fprintf(tw, "Fitness trajectory:\t%v\n", r.FitnessTrajectory)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Part\tArea\tAngle\tX\tY\tBounds")
+	for _, p := range r.Parts {
+		fmt.Fprintf(tw, "%d\t%.4f\t%.1f\t%.4f\t%.4f\t(%.4f, %.4f) - (%.4f, %.4f)\n",
+			p.Index, p.Area, p.Angle, p.X, p.Y, p.Bounds.MinX, p.Bounds.MinY, p.Bounds.MaxX, p.Bounds.MaxY)
+	}
+	return tw.Flush()
+}
+
+func (r *Report) writeCombinedText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Groups:\t%d\n", len(r.Groups))
+	fmt.Fprintf(tw, "Sheet area:\t%.4f\n", r.SheetArea)
+	fmt.Fprintf(tw, "Parts area:\t%.4f\n", r.PartsArea)
+	fmt.Fprintf(tw, "Utilization:\t%.2f%%\n", r.Utilization)
+	fmt.Fprintf(tw, "Placed parts:\t%d\n", r.Placed)
+	fmt.Fprintf(tw, "Unplaced parts:\t%d\n", r.Unplaced)
+	if len(r.Leftovers) > 0 {
+		fmt.Fprintf(tw, "Leftover parts:\t%v\n", r.Leftovers)
+	}
+	fmt.Fprintf(tw, "Runtime:\t%.2fs\n", r.RuntimeSeconds)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, group := range r.Groups {
+		fmt.Fprintln(w)
+		if err := group.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
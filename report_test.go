@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReport(t *testing.T) {
+	square := NewPolygon(NewRectangle(0, 0, 2, 2))
+	triangle := NewPolygon(Ring{{0, 0}, {0, 2}, {2, 0}, {0, 0}})
+	parts := []*Part{
+		{Shape: square, Orientations: []orientation{{shape: square}}, Offset: Offset{column: 0}},
+		{Shape: triangle, Orientations: []orientation{{shape: triangle, angle: 90}}, Offset: Offset{column: 2}},
+	}
+
+	report := NewReport(parts, []int{1, 0}, 1, 4, 2)
+
+	assert.Equal(t, 8.0, report.SheetArea)
+	assert.InDelta(t, 6, report.PartsArea, epsilon)
+	assert.InDelta(t, 75, report.Utilization, epsilon)
+	assert.Equal(t, Bounds{MinX: 0, MinY: 0, MaxX: 4, MaxY: 2}, report.Envelope)
+	// the hull follows the slope of the triangle
+	assert.InDelta(t, 6, report.HullArea, epsilon)
+	assert.InDelta(t, 100, report.HullUtilization, epsilon)
+	assert.Equal(t, 2, report.Placed)
+	assert.Equal(t, 0, report.Unplaced)
+
+	assert.Len(t, report.Parts, 2)
+	assert.Equal(t, PartReport{
+		Index:  0,
+		Area:   2,
+		Angle:  90,
+		X:      2,
+		Y:      0,
+		Bounds: Bounds{MinX: 2, MinY: 0, MaxX: 4, MaxY: 2},
+	}, report.Parts[1])
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteJSON(&buf))
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *report, decoded)
+
+	buf.Reset()
+	assert.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Utilization:")
+	assert.Contains(t, buf.String(), "75.00%")
+}
+
+func TestCombineReports(t *testing.T) {
+	steel := &Report{
+		Material:  "steel",
+		SheetArea: 10,
+		PartsArea: 5,
+		Placed:    1,
+		Unplaced:  1,
+		Parts:     []PartReport{{Index: 2, Area: 5}},
+		Leftovers: []int{3},
+	}
+	wood := &Report{
+		SheetArea: 30,
+		PartsArea: 25,
+		Placed:    2,
+		Unplaced:  1,
+		Parts:     []PartReport{{Index: 0, Area: 10}, {Index: 1, Area: 15}},
+		Leftovers: []int{4},
+	}
+
+	report := CombineReports([]*Report{steel, wood})
+
+	assert.Equal(t, 40.0, report.SheetArea)
+	assert.Equal(t, 30.0, report.PartsArea)
+	assert.InDelta(t, 75, report.Utilization, epsilon)
+	assert.Equal(t, 3, report.Placed)
+	assert.Equal(t, 2, report.Unplaced)
+	assert.Len(t, report.Parts, 3)
+	assert.Equal(t, []int{3, 4}, report.Leftovers)
+	assert.Equal(t, []*Report{steel, wood}, report.Groups)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Groups:")
+	assert.Contains(t, buf.String(), "Material:")
+	assert.Contains(t, buf.String(), "steel")
+}
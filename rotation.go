@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// refineRotations tries the intermediate angles around the angle of each placed part.
+// The step between the angles is halved at each level, the new orientation is kept
+// if it improves the fitness of the order. The angles stay within the min and max angles.
+// It returns the fitness of the order with the refined orientations.
+func refineRotations(
+	parts []*Part, order []int, fitness orderFitness,
+	step, minAngle, maxAngle float64, levels int,
+	newOrientation func(fig Polygon, angle float64) orientation,
+) float32 {
+	bestFitness := fitness(order)
+	angles := placedAngles(parts, order)
+
+	for level := 1; level <= levels; level++ {
+		delta := step / math.Pow(2, float64(level))
+		for _, num := range order {
+			part := parts[num]
+			for _, angle := range []float64{angles[num] - delta, angles[num] + delta} {
+				if angle < minAngle-epsilon || angle > maxAngle+epsilon {
+					continue
+				}
+
+				orientations := part.Orientations
+				part.Orientations = append(slices.Clone(orientations), newOrientation(part.Shape, angle))
+				if f := fitness(order); f > bestFitness {
+					bestFitness = f
+					angles = placedAngles(parts, order)
+					continue
+				}
+				part.Orientations = orientations
+			}
+		}
+		fmt.Printf("Rotation refinement level %d, step %f, fitness: %f\n", level, delta, bestFitness)
+	}
+
+	return bestFitness
+}
+
+// placedAngles returns the angles of the orientations the parts with the numbers are placed with,
+// the other parts may be placed concurrently, so they are not read
+func placedAngles(parts []*Part, nums []int) []float64 {
+	angles := make([]float64, len(parts))
+	for _, num := range nums {
+		angles[num] = parts[num].bestOrienation().angle
+	}
+	return angles
+}
+
+// distinctAngles returns the angles without the ones congruent to an angle before them,
+// so the full turn does not repeat the orientation of the zero angle
+func distinctAngles(angles []int) []int {
+	var distinct []int
+	seen := map[int]bool{}
+	for _, angle := range angles {
+		turn := (angle%360 + 360) % 360
+		if seen[turn] {
+			continue
+		}
+		seen[turn] = true
+		distinct = append(distinct, angle)
+	}
+	return distinct
+}
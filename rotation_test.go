@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefineRotations(t *testing.T) {
+	const target = 10.0
+
+	parts := []*Part{
+		{Orientations: []orientation{{angle: 0}, {angle: 15}}},
+		{Orientations: []orientation{{angle: 0}, {angle: 15}}},
+	}
+
+	// the placement prefers the orientations closest to the target angle
+	fitness := func(order []int) float32 {
+		var f float64
+		for _, num := range order {
+			part := parts[num]
+			for i, o := range part.Orientations {
+				if math.Abs(o.angle-target) < math.Abs(part.bestOrienation().angle-target) {
+					part.BestOrientationNum = i
+				}
+			}
+			f -= math.Abs(part.bestOrienation().angle - target)
+		}
+		return float32(f)
+	}
+	newOrientation := func(_ Polygon, angle float64) orientation {
+		return orientation{angle: angle}
+	}
+
+	got := refineRotations(parts, []int{1, 0}, fitness, 15, 0, 15, 3, newOrientation)
+
+	// 15 -> 7.5 -> 11.25 -> 9.375
+	assert.InDelta(t, -2*0.625, got, epsilon)
+	for _, part := range parts {
+		assert.Len(t, part.Orientations, 5)
+	}
+}
+
+func TestDistinctAngles(t *testing.T) {
+	tests := []struct {
+		name     string
+		angles   []int
+		expected []int
+	}{
+		{
+			name:     "full turn",
+			angles:   rangeSlice(0, 361, 90),
+			expected: []int{0, 90, 180, 270},
+		},
+		{
+			name:     "negative angles",
+			angles:   []int{-90, 0, 270, 720},
+			expected: []int{-90, 0},
+		},
+		{
+			name: "no angles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, distinctAngles(tt.angles))
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	simplifyDouglasPeucker = "dp"
+	simplifyVisvalingam    = "vw"
+)
+
+// the max distance of the vertex of the pushed out ring from the simplified vertex
+// in the distances the edges are pushed, the sharper corners are beveled
+const simplifyMiterLimit = 4
+
+// Simplify reduces the number of vertices of the polygon. The vertices deviating
+// from the simplified contour by less than the tolerance are removed, then the edges
+// are pushed away from the material to the farthest removed vertex, so the simplified
+// polygon still contains the original one: the outer ring only grows and the holes only shrink.
+func (p Polygon) Simplify(method string, tolerance float64) Polygon {
+	simplify := Ring.SimplifyDouglasPeucker
+	if method == simplifyVisvalingam {
+		simplify = Ring.SimplifyVisvalingam
+	}
+
+	var inners []Ring
+	for _, innerRing := range p.innerRings {
+		inners = append(inners, simplify(innerRing, tolerance, true))
+	}
+	return NewPolygon(simplify(p.outerRing, tolerance, false), inners...)
+}
+
+// SimplifyDouglasPeucker simplifies the closed ring with the Douglas-Peucker algorithm
+// keeping the material side of the ring. The material lies inside the outer ring and outside the hole.
+// https://en.wikipedia.org/wiki/Ramer%E2%80%93Douglas%E2%80%93Peucker_algorithm
+func (r Ring) SimplifyDouglasPeucker(tolerance float64, hole bool) Ring {
+	if !r.IsClosed() || len(r) < 5 {
+		return r
+	}
+
+	// the ring is split into two chains by the vertex farthest from the first one
+	farthest := 0
+	for i := range r {
+		if r[0].Distance(r[i]) > r[0].Distance(r[farthest]) {
+			farthest = i
+		}
+	}
+
+	keep := make([]bool, len(r))
+	keep[0], keep[farthest], keep[len(r)-1] = true, true, true
+	r.douglasPeucker(0, farthest, tolerance, keep)
+	r.douglasPeucker(farthest, len(r)-1, tolerance, keep)
+
+	var kept []int
+	for i := range r {
+		if keep[i] {
+			kept = append(kept, i)
+		}
+	}
+	return r.pushOut(kept, (r.Area() > 0) != hole)
+}
+
+func (r Ring) douglasPeucker(from, to int, tolerance float64, keep []bool) {
+	if to-from < 2 {
+		return
+	}
+
+	a, b := r[from], r[to]
+	split, maxDist := from+1, 0.0
+	for i := from + 1; i < to; i++ {
+		if dist := distanceToSegment(r[i], a, b); dist > maxDist {
+			split, maxDist = i, dist
+		}
+	}
+
+	if maxDist <= tolerance && !r.chordCrosses(from, to) {
+		return
+	}
+
+	keep[split] = true
+	r.douglasPeucker(from, split, tolerance, keep)
+	r.douglasPeucker(split, to, tolerance, keep)
+}
+
+// SimplifyVisvalingam simplifies the closed ring with the Visvalingam-Whyatt algorithm
+// keeping the material side of the ring. The vertex forming the triangle of the least area
+// with its neighbours is removed while the vertex and the vertices removed before between
+// the neighbours are within the tolerance of the chord.
+// https://en.wikipedia.org/wiki/Visvalingam%E2%80%93Whyatt_algorithm
+func (r Ring) SimplifyVisvalingam(tolerance float64, hole bool) Ring {
+	if !r.IsClosed() || len(r) < 5 {
+		return r
+	}
+
+	// the numbers of the kept vertices of the open ring
+	kept := make([]int, len(r)-1)
+	for i := range kept {
+		kept[i] = i
+	}
+
+	for len(kept) > 3 {
+		best, bestArea := -1, math.Inf(1)
+		n := len(kept)
+		for i := range kept {
+			prev, pt, next := r[kept[(i+n-1)%n]], r[kept[i]], r[kept[(i+1)%n]]
+			if !r.withinChord(kept[(i+n-1)%n], kept[(i+1)%n], tolerance) {
+				continue
+			}
+
+			area := math.Abs(cross(
+				NewPoint(pt.X-prev.X, pt.Y-prev.Y),
+				NewPoint(next.X-prev.X, next.Y-prev.Y),
+			)) / 2
+			if area >= bestArea {
+				continue
+			}
+
+			candidate := append(slices.Clone(kept[:i]), kept[i+1:]...)
+			chord := (i + n - 1) % n
+			if i == 0 {
+				chord = len(candidate) - 1
+			}
+			points := make([]Point, len(candidate))
+			for j, num := range candidate {
+				points[j] = r[num]
+			}
+			if closeRing(points).chordCrosses(chord, chord+1) {
+				continue
+			}
+			best, bestArea = i, area
+		}
+
+		if best == -1 {
+			break
+		}
+		kept = slices.Delete(kept, best, best+1)
+	}
+
+	if kept[0] != 0 {
+		// the ring starts at the first kept vertex
+		first := kept[0]
+		r = closeRing(append(slices.Clone(r[first:len(r)-1]), r[:first]...))
+		for i := range kept {
+			kept[i] -= first
+		}
+	}
+	return r.pushOut(append(kept, len(r)-1), (r.Area() > 0) != hole)
+}
+
+// pushOut returns the ring through the kept vertices of the closed ring, the first and the last
+// vertices are kept. Each edge is moved away from the material to the farthest vertex
+// of the ring between its ends, so the returned ring contains the material of the given one.
+func (r Ring) pushOut(kept []int, materialRight bool) Ring {
+	vertices := make(Ring, len(kept))
+	for j, i := range kept {
+		vertices[j] = r[i]
+	}
+
+	shifts := make([]float64, len(kept)-1)
+	for j := range shifts {
+		a, b := r[kept[j]], r[kept[j+1]]
+		// the normal points to the scrap
+		n := normal(direction(a, b), materialRight)
+		for i := kept[j] + 1; i < kept[j+1]; i++ {
+			shifts[j] = max(shifts[j], dot(n, NewPoint(r[i].X-a.X, r[i].Y-a.Y)))
+		}
+	}
+	return vertices.offsetEdges(shifts, materialRight)
+}
+
+// offsetEdges returns the closed ring with each edge moved to the scrap by its shift,
+// the adjacent moved edges meet at their intersection unless the corner is too sharp
+func (r Ring) offsetEdges(shifts []float64, materialRight bool) Ring {
+	edges := len(r) - 1
+	normals := make([]Point, edges)
+	for j := range normals {
+		normals[j] = normal(direction(r[j], r[j+1]), materialRight)
+	}
+
+	var pushed Ring
+	for j := 0; j < edges; j++ {
+		prev := (j + edges - 1) % edges
+		vertex := r[j]
+		if shifts[prev] == 0 && shifts[j] == 0 {
+			pushed = append(pushed, vertex)
+			continue
+		}
+
+		from := add(vertex, scale(normals[prev], shifts[prev]))
+		to := add(vertex, scale(normals[j], shifts[j]))
+		corner, ok := lineIntersection(from, direction(r[prev], vertex), to, direction(vertex, r[j+1]))
+		if ok && corner.Distance(vertex) <= simplifyMiterLimit*max(shifts[prev], shifts[j]) {
+			pushed = append(pushed, corner)
+			continue
+		}
+		// the edges are almost parallel or meet at a sharp corner
+		pushed = append(pushed, from, to)
+	}
+	return closeRing(pushed)
+}
+
+// lineIntersection returns the intersection of the lines through the points in the directions,
+// it returns false if the lines are parallel
+func lineIntersection(a, dirA, b, dirB Point) (Point, bool) {
+	denominator := cross(dirA, dirB)
+	if math.Abs(denominator) < epsilon {
+		return Point{}, false
+	}
+	t := cross(NewPoint(b.X-a.X, b.Y-a.Y), dirB) / denominator
+	return add(a, scale(dirA, t)), true
+}
+
+// withinChord reports whether the vertices between from and to of the closed ring are
+// within the tolerance of the chord from-to. The vertices are counted past the end of the ring.
+func (r Ring) withinChord(from, to int, tolerance float64) bool {
+	n := len(r) - 1
+	for i := (from + 1) % n; i != to; i = (i + 1) % n {
+		if distanceToSegment(r[i], r[from], r[to]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// chordCrosses returns true if the line between the vertices crosses any edge of the ring
+func (r Ring) chordCrosses(from, to int) bool {
+	chord := Line{Start: r[from], End: r[to]}
+	for i := 0; i < len(r)-1; i++ {
+		if (Line{Start: r[i], End: r[i+1]}).Crosses(chord) {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceToSegment returns the distance from the point to the segment between a and b
+func distanceToSegment(pt, a, b Point) float64 {
+	length := a.Distance(b)
+	if length == 0 {
+		return pt.Distance(a)
+	}
+	dir := direction(a, b)
+	t := dot(dir, NewPoint(pt.X-a.X, pt.Y-a.Y))
+	t = max(0, min(length, t))
+	return pt.Distance(add(a, scale(dir, t)))
+}
+
+func closeRing(points []Point) Ring {
+	return append(slices.Clone(Ring(points)), points[0])
+}
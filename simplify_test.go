@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolygon_Simplify(t *testing.T) {
+	tests := []struct {
+		name      string
+		poly      Polygon
+		tolerance float64
+		expected  Polygon
+	}{
+		{
+			name:      "dent is filled",
+			poly:      NewPolygon(Ring{{0, 0}, {0, 4}, {2, 3.9}, {4, 4}, {4, 0}, {0, 0}}),
+			tolerance: 0.5,
+			expected:  NewPolygon(Ring{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}),
+		},
+		{
+			name:      "edge is pushed out to the bump",
+			poly:      NewPolygon(Ring{{0, 0}, {0, 4}, {2, 4.1}, {4, 4}, {4, 0}, {0, 0}}),
+			tolerance: 0.5,
+			expected:  NewPolygon(Ring{{0, 0}, {0, 4.1}, {4, 4.1}, {4, 0}, {0, 0}}),
+		},
+		{
+			name: "bump of hole is filled",
+			poly: NewPolygon(
+				NewRectangle(0, 0, 10, 10),
+				Ring{{2, 2}, {2, 8}, {5, 8.1}, {8, 8}, {8, 2}, {2, 2}},
+			),
+			tolerance: 0.5,
+			expected: NewPolygon(
+				NewRectangle(0, 0, 10, 10),
+				Ring{{2, 2}, {2, 8}, {8, 8}, {8, 2}, {2, 2}},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		for _, method := range []string{simplifyDouglasPeucker, simplifyVisvalingam} {
+			t.Run(tt.name+" "+method, func(t *testing.T) {
+				got := tt.poly.Simplify(method, tt.tolerance)
+				assert.Equal(t, tt.expected, got)
+			})
+		}
+	}
+}
+
+func TestPolygon_SimplifyContainsOriginal(t *testing.T) {
+	// a star with many small teeth
+	var star Ring
+	for i := 0; i < 200; i++ {
+		angle := -2 * math.Pi * float64(i) / 200
+		radius := 10 + 0.1*float64(i%2) + math.Sin(float64(i)/7)
+		star = append(star, NewPoint(radius*math.Cos(angle), radius*math.Sin(angle)))
+	}
+	star = append(star, star[0])
+	poly := NewPolygon(star)
+
+	for _, method := range []string{simplifyDouglasPeucker, simplifyVisvalingam} {
+		t.Run(method, func(t *testing.T) {
+			got := poly.Simplify(method, 0.5)
+
+			assert.Less(t, len(got.outerRing), len(star))
+			assertContainsRing(t, got, star)
+		})
+	}
+}
+
+func TestPolygon_SimplifyConvex(t *testing.T) {
+	var circle Ring
+	for i := 0; i < 64; i++ {
+		angle := -2 * math.Pi * float64(i) / 64
+		circle = append(circle, NewPoint(10*math.Cos(angle), 10*math.Sin(angle)))
+	}
+	circle = append(circle, circle[0])
+	poly := NewPolygon(circle)
+
+	for _, method := range []string{simplifyDouglasPeucker, simplifyVisvalingam} {
+		t.Run(method, func(t *testing.T) {
+			got := poly.Simplify(method, 1)
+
+			assert.Less(t, len(got.outerRing), 20)
+			assertContainsRing(t, got, circle)
+		})
+	}
+}
+
+// assertContainsRing checks that the simplified polygon contains the original ring
+func assertContainsRing(t *testing.T, got Polygon, ring Ring) {
+	assert.GreaterOrEqual(t, got.Area(), NewPolygon(ring).Area())
+	for _, pt := range ring {
+		onBoundary := false
+		for i := 0; i < len(got.outerRing)-1; i++ {
+			if distanceToSegment(pt, got.outerRing[i], got.outerRing[i+1]) < epsilon {
+				onBoundary = true
+			}
+		}
+		assert.True(t, onBoundary || got.Contains(pt), "point %v is outside", pt)
+	}
+}
+
+func TestRing_SimplifyVisvalingamWithinTolerance(t *testing.T) {
+	// the bump at 1.2 is within the tolerance of the chord to the vertex at 0.9,
+	// but not of the top edge left after the vertex at 0.9 is removed as well
+	ring := Ring{{0, 0}, {0, 100}, {40, 101.2}, {41, 100.9}, {100, 100}, {100, 0}, {0, 0}}
+
+	got := ring.SimplifyVisvalingam(1, false)
+	assert.Len(t, got, 7)
+	assertContainsRing(t, NewPolygon(got), ring)
+}
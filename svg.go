@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 )
 
@@ -98,6 +99,79 @@ func (d *SVGDrawer) AddPolygon(poly Polygon, styles ...string) {
 	d.buffer.WriteString("\n")
 }
 
+// AddShape draws the shape with the true arcs and Bezier curves
+func (d *SVGDrawer) AddShape(shape Shape, styles ...string) {
+	d.buffer.WriteString(`<path d="`)
+	for _, contour := range shape.Contours() {
+		d.buffer.WriteString(fmt.Sprintf("M%f,%f ", contour.Start.X, contour.Start.Y))
+		current := contour.Start
+		for _, curve := range contour.Curves {
+			d.writeCurve(current, curve)
+			current = curve.End()
+		}
+		d.buffer.WriteString("Z")
+	}
+	d.buffer.WriteString(`" `)
+	for i := 0; i < len(styles); i += 2 {
+		d.buffer.WriteString(fmt.Sprintf(`%s="%s" `, styles[i], styles[i+1]))
+	}
+	d.buffer.WriteString(`fill="none" />`)
+	d.buffer.WriteString("\n")
+}
+
+func (d *SVGDrawer) writeCurve(start Point, curve Curve) {
+	switch c := curve.(type) {
+	case ArcSegment:
+		sweep := c.Sweep(start)
+		radius := start.Distance(c.Center)
+		// the arc with the same start and end points is not drawn, so the circle is split in halves
+		if math.Abs(sweep) > 2*math.Pi-epsilon {
+			middle := Ring{start}.Rotate(180, c.Center)[0]
+			d.writeArc(radius, sweep/2, middle)
+			sweep /= 2
+		}
+		d.writeArc(radius, sweep, c.To)
+	case QuadBezier:
+		d.buffer.WriteString(fmt.Sprintf("Q%f,%f %f,%f ", c.Control.X, c.Control.Y, c.To.X, c.To.Y))
+	case CubicBezier:
+		d.buffer.WriteString(fmt.Sprintf("C%f,%f %f,%f %f,%f ",
+			c.Control1.X, c.Control1.Y, c.Control2.X, c.Control2.Y, c.To.X, c.To.Y))
+	default:
+		d.buffer.WriteString(fmt.Sprintf("L%f,%f ", curve.End().X, curve.End().Y))
+	}
+}
+
+func (d *SVGDrawer) writeArc(radius, sweep float64, to Point) {
+	largeArc, positive := 0, 0
+	if math.Abs(sweep) > math.Pi {
+		largeArc = 1
+	}
+	// the positive angle direction in the user space is counter-clockwise
+	if sweep > 0 {
+		positive = 1
+	}
+	d.buffer.WriteString(fmt.Sprintf("A%f,%f 0 %d,%d %f,%f ", radius, radius, largeArc, positive, to.X, to.Y))
+}
+
+func (d *SVGDrawer) AddPolyline(points []Point, styles ...string) {
+	if len(points) == 0 {
+		return
+	}
+	d.buffer.WriteString(`<polyline points="`)
+	for i, pt := range points {
+		d.buffer.WriteString(fmt.Sprintf("%f,%f", pt.X, pt.Y))
+		if i < len(points)-1 {
+			d.buffer.WriteString(" ")
+		}
+	}
+	d.buffer.WriteString(`" `)
+	for i := 0; i < len(styles); i += 2 {
+		d.buffer.WriteString(fmt.Sprintf(`%s="%s" `, styles[i], styles[i+1]))
+	}
+	d.buffer.WriteString(`fill="none" />`)
+	d.buffer.WriteString("\n")
+}
+
 func (d *SVGDrawer) AddPart(piece OccupancyTable, step float64, offset Point, styles ...string) {
 	for i, segment := range piece {
 		for _, interval := range segment {
@@ -110,6 +184,17 @@ func (d *SVGDrawer) AddPart(piece OccupancyTable, step float64, offset Point, st
 	}
 }
 
+// AddHorizontalPart draws the part discretized into the horizontal strips
+func (d *SVGDrawer) AddHorizontalPart(piece OccupancyTable, step float64, offset Point, styles ...string) {
+	for i, segment := range piece {
+		for _, interval := range segment {
+			x := offset.X + interval.Start
+			y := offset.Y + float64(i)*step
+			d.AddSquare(x, y, interval.End-interval.Start, step, styles...)
+		}
+	}
+}
+
 func (d *SVGDrawer) AddSquare(x, y, width, height float64, styles ...string) {
 	d.buffer.WriteString(`<rect x="`)
 	d.buffer.WriteString(fmt.Sprintf("%f", x))
@@ -0,0 +1,116 @@
+package main
+
+import "fmt"
+
+// the distance from an edge to the point used to probe the material near the edge
+const probeDistance = 0.001
+
+// Violation describes a placed part that overlaps another part or leaves the sheet
+type Violation struct {
+	// the indexes of the parts
+	Parts   []int
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("parts %v: %s", v.Parts, v.Message)
+}
+
+// ValidatePlacement checks that the placed parts lie inside the sheet and
+// do not overlap each other. The raster occupancy tables over-approximate the parts,
+// so the check uses the exact polygons.
+func ValidatePlacement(parts []Polygon, length, height float64) []Violation {
+	var violations []Violation
+
+	for i, part := range parts {
+		minx, miny, maxx, maxy := part.Bounds()
+		if minx < -epsilon || miny < -epsilon || maxx > length+epsilon || maxy > height+epsilon {
+			violations = append(violations, Violation{
+				Parts: []int{i},
+				Message: fmt.Sprintf("bounds (%f, %f, %f, %f) are outside the sheet %fx%f",
+					minx, miny, maxx, maxy, length, height),
+			})
+		}
+	}
+
+	for i := 0; i < len(parts); i++ {
+		for j := i + 1; j < len(parts); j++ {
+			if overlaps(parts[i], parts[j]) {
+				violations = append(violations, Violation{
+					Parts:   []int{i, j},
+					Message: "parts overlap",
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// ValidateWithin checks that the placed parts lie inside the outline, e.g. of a remnant,
+// and do not overlap each other. The material around the outline is checked as another part.
+func ValidateWithin(parts []Polygon, outline Ring) []Violation {
+	minx, miny, maxx, maxy := NewPolygon(outline).Bounds()
+	violations := ValidatePlacement(parts, maxx, maxy)
+
+	around := NewPolygon(NewRectangle(minx-1, miny-1, maxy-miny+2, maxx-minx+2), outline)
+	for i, part := range parts {
+		if overlaps(part, around) {
+			violations = append(violations, Violation{
+				Parts:   []int{i},
+				Message: "part is outside the outline",
+			})
+		}
+	}
+	return violations
+}
+
+// overlaps returns true if the interiors of the polygons intersect
+func overlaps(a, b Polygon) bool {
+	if !boundsTouch(a, b, 0) {
+		return false
+	}
+
+	for _, ra := range a.rings() {
+		for _, rb := range b.rings() {
+			if ringsCross(ra, rb) {
+				return true
+			}
+		}
+	}
+
+	// the polygons may overlap without crossing edges,
+	// when one lies inside the other or their edges coincide
+	return probesInside(a, b) || probesInside(b, a)
+}
+
+func ringsCross(a, b Ring) bool {
+	for i := 0; i < len(a)-1; i++ {
+		for j := 0; j < len(b)-1; j++ {
+			if (Line{Start: a[i], End: a[i+1]}).Crosses(Line{Start: b[j], End: b[j+1]}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// probesInside returns true if the material of the polygon a near the middle
+// of one of its edges lies inside the polygon b
+func probesInside(a, b Polygon) bool {
+	for k, ring := range a.rings() {
+		// the material is on the right side of the clockwise outer ring
+		right := (ring.Area() > 0) == (k == 0)
+		for i := 0; i < len(ring)-1; i++ {
+			if ring[i] == ring[i+1] {
+				continue
+			}
+			middle := interpolate(ring[i], ring[i+1], 0.5)
+			probe := add(middle, scale(normal(direction(ring[i], ring[i+1]), !right), probeDistance))
+			if b.Contains(probe) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePlacement(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []Polygon
+		expected [][]int
+	}{
+		{
+			name: "adjacent squares",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(2, 0, 2, 2)),
+			},
+		},
+		{
+			name: "crossing squares",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(1, 1, 2, 2)),
+			},
+			expected: [][]int{{0, 1}},
+		},
+		{
+			name: "coincident squares",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+				NewPolygon(NewRectangle(0, 0, 2, 2)),
+			},
+			expected: [][]int{{0, 1}},
+		},
+		{
+			name: "square inside square",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 6, 6)),
+				NewPolygon(NewRectangle(2, 2, 2, 2)),
+			},
+			expected: [][]int{{0, 1}},
+		},
+		{
+			name: "square inside hole",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 6, 6), NewRectangle(1, 1, 4, 4)),
+				NewPolygon(NewRectangle(2, 2, 2, 2)),
+			},
+		},
+		{
+			name: "outside the sheet",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(9, 0, 2, 2)),
+			},
+			expected: [][]int{{0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int
+			for _, v := range ValidatePlacement(tt.parts, 10, 10) {
+				got = append(got, v.Parts)
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestValidateWithin(t *testing.T) {
+	// the L-shaped remnant without the top right quarter
+	outline := Ring{{0, 0}, {0, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 0}, {0, 0}}
+
+	tests := []struct {
+		name     string
+		parts    []Polygon
+		expected [][]int
+	}{
+		{
+			name: "parts inside the outline",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(0, 0, 4, 2)),
+				NewPolygon(NewRectangle(2, 0, 2, 2)),
+			},
+		},
+		{
+			name: "part in the cut corner",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(2, 2, 2, 2)),
+			},
+			expected: [][]int{{0}},
+		},
+		{
+			name: "part across the inner corner",
+			parts: []Polygon{
+				NewPolygon(NewRectangle(1, 1, 2, 2)),
+			},
+			expected: [][]int{{0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int
+			for _, v := range ValidateWithin(tt.parts, outline) {
+				got = append(got, v.Parts)
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestBottomLeftFill_ValidPlacement(t *testing.T) {
+	const step = 0.5
+
+	shapes := []Polygon{
+		NewPolygon(NewRectangle(0, 0, 4, 3)),
+		NewPolygon(Ring{{0, 0}, {1, 3}, {4, 0}, {0, 0}}),
+		NewPolygon(NewRectangle(0, 0, 10, 10), NewRectangle(2.5, 2.5, 5, 5)),
+		NewPolygon(NewRectangle(0, 0, 2, 2)),
+		NewPolygon(Ring{{0, 2}, {2, 4}, {4, 2}, {2, 0}, {0, 2}}),
+	}
+
+	var parts []*Part
+	for _, shape := range shapes {
+		parts = append(parts, &Part{
+			Orientations: []orientation{{shape: shape, occupancy: Discretize(shape, step)}},
+			Shape:        shape,
+		})
+	}
+
+	NewBottomLeftFill(10, 100).Run(parts)
+
+	placed := placedShapes(parts, step)
+	assert.Empty(t, ValidatePlacement(placed, 100*step, 10))
+}